@@ -0,0 +1,91 @@
+package activation
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+
+	"github.com/vitalvas/go-license/license"
+)
+
+// CertificateBlockType is the PEM block type of activation certificates. The block type is covered
+// by the vendor signature, so a certificate is never accepted as a license key.
+const CertificateBlockType = "LICENSE ACTIVATION CERTIFICATE"
+
+// Certificate binds a license to a machine. It is a PEM block of type CertificateBlockType
+// signed by the vendor, so it can be verified offline with license.Verifier.
+type Certificate struct {
+	ID        string `json:"id"`            // Activation ID
+	LicenseID string `json:"lid"`           // Activated License ID
+	Machine   string `json:"mch"`           // Machine Fingerprint
+	IssuedAt  int64  `json:"iat,omitempty"` // Issued At
+	ExpiredAt int64  `json:"exp,omitempty"` // Expires At, copied from the activated license
+}
+
+// Encode signs the certificate with the vendor ed25519 private key and returns it PEM encoded.
+func (c *Certificate) Encode(privateKey ed25519.PrivateKey) ([]byte, error) {
+	if len(c.ID) == 0 || len(c.LicenseID) == 0 {
+		return nil, license.ErrLicenseIDNotDefined
+	}
+
+	if len(c.Machine) == 0 {
+		return nil, ErrMachineRequired
+	}
+
+	if c.ExpiredAt > 0 && c.ExpiredAt <= c.IssuedAt {
+		return nil, license.ErrTime
+	}
+
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return license.EncodeBlock(CertificateBlockType, nil, payload, privateKey)
+}
+
+// DecodeCertificate decodes the PEM encoded activation certificate and verifies its signature.
+func DecodeCertificate(data []byte, verifier *license.Verifier) (*Certificate, error) {
+	_, payload, err := verifier.VerifyBlock(data, CertificateBlockType)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeCertificatePayload(payload)
+}
+
+// DecodeCertificateFile decodes the PEM encoded activation certificate file and verifies its signature.
+// The file is read up to the MaxInputBytes decode option of the verifier.
+func DecodeCertificateFile(path string, verifier *license.Verifier) (*Certificate, error) {
+	_, payload, err := verifier.VerifyBlockFile(path, CertificateBlockType)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeCertificatePayload(payload)
+}
+
+func decodeCertificatePayload(payload []byte) (*Certificate, error) {
+	var c Certificate
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, err
+	}
+
+	if len(c.ID) == 0 || len(c.LicenseID) == 0 || len(c.Machine) == 0 {
+		return nil, ErrNotCertificate
+	}
+
+	return &c, nil
+}
+
+// Check verifies that the certificate activates the license on the machine.
+func (c *Certificate) Check(licenseID, machine string) error {
+	if c.LicenseID != licenseID {
+		return ErrLicenseMismatch
+	}
+
+	if c.Machine != machine {
+		return ErrMachineMismatch
+	}
+
+	return nil
+}
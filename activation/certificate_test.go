@@ -0,0 +1,117 @@
+package activation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/go-license/license"
+)
+
+func TestCertificate_Encode_Decode(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	verifier, err := license.NewVerifier(publicKey)
+	require.NoError(t, err)
+
+	cert := &Certificate{
+		ID:        "activation-1",
+		LicenseID: "license-1",
+		Machine:   "machine-1",
+		IssuedAt:  time.Now().Unix(),
+		ExpiredAt: time.Now().Add(time.Hour).Unix(),
+	}
+
+	encoded, err := cert.Encode(privateKey)
+	require.NoError(t, err)
+
+	t.Run("decode", func(t *testing.T) {
+		decoded, err := DecodeCertificate(encoded, verifier)
+		require.NoError(t, err)
+		assert.Equal(t, cert, decoded)
+		assert.NoError(t, decoded.Check("license-1", "machine-1"))
+		assert.ErrorIs(t, decoded.Check("license-2", "machine-1"), ErrLicenseMismatch)
+		assert.ErrorIs(t, decoded.Check("license-1", "machine-2"), ErrMachineMismatch)
+	})
+
+	t.Run("not a license key", func(t *testing.T) {
+		_, err := verifier.Verify(encoded)
+		assert.ErrorIs(t, err, license.ErrMalformedLicense)
+
+		block, _ := pem.Decode(encoded)
+		require.NotNil(t, block)
+
+		block.Type = "LICENSE KEY"
+
+		_, err = verifier.Verify(pem.EncodeToMemory(block))
+		assert.ErrorIs(t, err, license.ErrVerifySignature)
+	})
+
+	t.Run("decode from file", func(t *testing.T) {
+		tempFile, err := os.CreateTemp("", "activation_*.pem")
+		require.NoError(t, err)
+		defer os.Remove(tempFile.Name())
+
+		_, err = tempFile.Write(encoded)
+		require.NoError(t, err)
+		tempFile.Close()
+
+		decoded, err := DecodeCertificateFile(tempFile.Name(), verifier)
+		require.NoError(t, err)
+		assert.Equal(t, cert, decoded)
+
+		_, err = DecodeCertificateFile(tempFile.Name(), verifier.WithDecodeOptions(license.DecodeOptions{MaxInputBytes: 16}))
+		assert.ErrorIs(t, err, license.ErrLimitExceeded)
+	})
+
+	t.Run("untrusted key", func(t *testing.T) {
+		otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		otherVerifier, err := license.NewVerifier(otherPublicKey)
+		require.NoError(t, err)
+
+		_, err = DecodeCertificate(encoded, otherVerifier)
+		assert.ErrorIs(t, err, license.ErrVerifySignature)
+	})
+
+	t.Run("license is not a certificate", func(t *testing.T) {
+		lic := &license.License{ID: "license-1"}
+		encodedLicense, err := lic.Encode(privateKey)
+		require.NoError(t, err)
+
+		_, err = DecodeCertificate(encodedLicense, verifier)
+		assert.ErrorIs(t, err, license.ErrMalformedLicense)
+	})
+
+	t.Run("block without certificate claims", func(t *testing.T) {
+		encodedBlock, err := license.EncodeBlock(CertificateBlockType, nil, []byte(`{"id":"activation-1"}`), privateKey)
+		require.NoError(t, err)
+
+		_, err = DecodeCertificate(encodedBlock, verifier)
+		assert.ErrorIs(t, err, ErrNotCertificate)
+	})
+}
+
+func TestCertificate_Encode_Errors(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, err = (&Certificate{ID: "a", Machine: "m"}).Encode(privateKey)
+	assert.ErrorIs(t, err, license.ErrLicenseIDNotDefined)
+
+	_, err = (&Certificate{ID: "a", LicenseID: "l"}).Encode(privateKey)
+	assert.ErrorIs(t, err, ErrMachineRequired)
+
+	_, err = (&Certificate{LicenseID: "l", Machine: "m"}).Encode(privateKey)
+	assert.ErrorIs(t, err, license.ErrLicenseIDNotDefined)
+
+	_, err = (&Certificate{ID: "a", LicenseID: "l", Machine: "m", IssuedAt: 2, ExpiredAt: 1}).Encode(privateKey)
+	assert.ErrorIs(t, err, license.ErrTime)
+}
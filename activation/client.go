@@ -0,0 +1,111 @@
+package activation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/vitalvas/go-license/license"
+)
+
+// Client activates licenses against an activation Server.
+type Client struct {
+	baseURL    string
+	verifier   *license.Verifier
+	httpClient *http.Client
+}
+
+// NewClient returns a Client for the server at baseURL. The verifier checks both the license
+// before it is submitted and the activation certificate returned by the server.
+// If httpClient is nil, http.DefaultClient is used.
+func NewClient(baseURL string, verifier *license.Verifier, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		verifier:   verifier,
+		httpClient: httpClient,
+	}
+}
+
+// Activate activates the license on the machine. It returns the verified activation certificate
+// and its PEM encoding, which the product stores to check the activation offline later.
+func (c *Client) Activate(ctx context.Context, licenseKey []byte, machine string) (*Certificate, []byte, error) {
+	lic, err := c.verifier.Verify(licenseKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var resp activateResponse
+	if err := c.do(ctx, ActivatePath, licenseKey, machine, &resp); err != nil {
+		return nil, nil, err
+	}
+
+	encoded := []byte(resp.Certificate)
+
+	cert, err := DecodeCertificate(encoded, c.verifier)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := cert.Check(lic.ID, machine); err != nil {
+		return nil, nil, err
+	}
+
+	return cert, encoded, nil
+}
+
+// Deactivate releases the activation of the license on the machine.
+func (c *Client) Deactivate(ctx context.Context, licenseKey []byte, machine string) error {
+	return c.do(ctx, DeactivatePath, licenseKey, machine, nil)
+}
+
+func (c *Client) do(ctx context.Context, path string, licenseKey []byte, machine string, out any) error {
+	if len(machine) == 0 {
+		return ErrMachineRequired
+	}
+
+	body, err := json.Marshal(activateRequest{
+		License: string(licenseKey),
+		Machine: machine,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	reader := io.LimitReader(resp.Body, maxRequestBytes)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var errResp errorResponse
+		if err := json.NewDecoder(reader).Decode(&errResp); err != nil {
+			return ErrServer
+		}
+
+		return errorFromCode(errResp.Error)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(reader).Decode(out)
+}
@@ -0,0 +1,18 @@
+package activation
+
+import "errors"
+
+var (
+	ErrInvalidRequest     = errors.New("invalid activation request")
+	ErrInvalidLicense     = errors.New("invalid license")
+	ErrLicenseExpired     = errors.New("license expired")
+	ErrActivationLimit    = errors.New("activation limit reached")
+	ErrActivationNotFound = errors.New("activation not found")
+	ErrMachineRequired    = errors.New("machine fingerprint required")
+	ErrMachineMismatch    = errors.New("machine fingerprint mismatch")
+	ErrLicenseMismatch    = errors.New("license mismatch")
+	ErrNonceMismatch      = errors.New("nonce mismatch")
+	ErrNotCertificate     = errors.New("not an activation certificate")
+	ErrServer             = errors.New("activation server error")
+	ErrStoreNotDefined    = errors.New("activation store not defined")
+)
@@ -0,0 +1,225 @@
+package activation
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"time"
+
+	"github.com/vitalvas/go-license/license"
+)
+
+// PEM block types of the offline activation flow.
+const (
+	RequestBlockType  = "LICENSE ACTIVATION REQUEST"
+	ResponseBlockType = "LICENSE ACTIVATION RESPONSE"
+)
+
+// requestKeyHeader carries the machine public key that signed an activation request.
+const requestKeyHeader = "key"
+
+// Request is an offline activation request written by the product and carried to the vendor.
+// It is signed with a machine key generated by the product.
+type Request struct {
+	LicenseID          string `json:"lid"`           // License ID
+	LicenseFingerprint string `json:"lfp"`           // License Fingerprint
+	Machine            string `json:"mch"`           // Machine Fingerprint
+	Nonce              string `json:"nce"`           // Random Nonce, echoed in the response
+	CreatedAt          int64  `json:"iat,omitempty"` // Created At
+}
+
+// NewRequest returns an activation request for the license on the machine with a random nonce.
+func NewRequest(lic *license.License, machine string) (*Request, error) {
+	if len(machine) == 0 {
+		return nil, ErrMachineRequired
+	}
+
+	fingerprint, err := lic.GetFingerprint()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return &Request{
+		LicenseID:          lic.ID,
+		LicenseFingerprint: fingerprint,
+		Machine:            machine,
+		Nonce:              base64.RawURLEncoding.EncodeToString(nonce),
+		CreatedAt:          time.Now().Unix(),
+	}, nil
+}
+
+// Encode signs the request with the machine ed25519 private key and returns it PEM encoded.
+func (r *Request) Encode(machineKey ed25519.PrivateKey) ([]byte, error) {
+	if machineKey == nil {
+		return nil, license.ErrPrivateKeyNotDefined
+	}
+
+	payload, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{
+		requestKeyHeader: base64.RawURLEncoding.EncodeToString(machineKey.Public().(ed25519.PublicKey)),
+	}
+
+	return license.EncodeBlock(RequestBlockType, headers, payload, machineKey)
+}
+
+// DecodeRequest decodes the PEM encoded activation request and verifies its signature
+// with the machine public key it carries.
+func DecodeRequest(data []byte) (*Request, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != RequestBlockType {
+		return nil, ErrInvalidRequest
+	}
+
+	machineKey, err := base64.RawURLEncoding.DecodeString(block.Headers[requestKeyHeader])
+	if err != nil || len(machineKey) != ed25519.PublicKeySize {
+		return nil, ErrInvalidRequest
+	}
+
+	_, payload, err := license.DecodeBlock(data, RequestBlockType, machineKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var req Request
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	if len(req.Nonce) == 0 || len(req.Machine) == 0 {
+		return nil, ErrInvalidRequest
+	}
+
+	return &req, nil
+}
+
+// Response is an offline activation response signed by the vendor.
+type Response struct {
+	Nonce              string `json:"nce"`           // Request Nonce
+	ID                 string `json:"id"`            // Activation ID
+	LicenseID          string `json:"lid"`           // License ID
+	LicenseFingerprint string `json:"lfp"`           // License Fingerprint
+	Machine            string `json:"mch"`           // Machine Fingerprint
+	IssuedAt           int64  `json:"iat,omitempty"` // Issued At
+	ExpiredAt          int64  `json:"exp,omitempty"` // Expires At
+}
+
+// Encode signs the response with the vendor ed25519 private key and returns it PEM encoded.
+func (r *Response) Encode(privateKey ed25519.PrivateKey) ([]byte, error) {
+	payload, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return license.EncodeBlock(ResponseBlockType, nil, payload, privateKey)
+}
+
+// DecodeResponse decodes the PEM encoded activation response and verifies the vendor signature.
+func DecodeResponse(data []byte, verifier *license.Verifier) (*Response, error) {
+	_, payload, err := verifier.VerifyBlock(data, ResponseBlockType)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponsePayload(payload)
+}
+
+func decodeResponsePayload(payload []byte) (*Response, error) {
+	var resp Response
+	if err := json.Unmarshal(payload, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// DecodeResponseFile decodes the PEM encoded activation response file and verifies the vendor signature.
+// The file is read up to the MaxInputBytes decode option of the verifier.
+func DecodeResponseFile(path string, verifier *license.Verifier) (*Response, error) {
+	_, payload, err := verifier.VerifyBlockFile(path, ResponseBlockType)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponsePayload(payload)
+}
+
+// Check verifies that the response answers the request.
+func (r *Response) Check(req *Request) error {
+	if r.Nonce != req.Nonce {
+		return ErrNonceMismatch
+	}
+
+	if r.LicenseID != req.LicenseID || r.LicenseFingerprint != req.LicenseFingerprint {
+		return ErrLicenseMismatch
+	}
+
+	if r.Machine != req.Machine {
+		return ErrMachineMismatch
+	}
+
+	return nil
+}
+
+// Certificate returns the activation certificate granted by the response.
+func (r *Response) Certificate() *Certificate {
+	return &Certificate{
+		ID:        r.ID,
+		LicenseID: r.LicenseID,
+		Machine:   r.Machine,
+		IssuedAt:  r.IssuedAt,
+		ExpiredAt: r.ExpiredAt,
+	}
+}
+
+// RespondOffline answers a PEM encoded offline activation request for the license key.
+// It applies the same license checks and activation limits as the online protocol
+// and returns the PEM encoded response.
+func (s *Server) RespondOffline(ctx context.Context, request, licenseKey []byte) ([]byte, error) {
+	req, err := DecodeRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	lic, err := s.checkLicense(licenseKey)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint, err := lic.GetFingerprint()
+	if err != nil {
+		return nil, err
+	}
+
+	if req.LicenseID != lic.ID || req.LicenseFingerprint != fingerprint {
+		return nil, ErrLicenseMismatch
+	}
+
+	activation, err := s.activate(ctx, lic, req.Machine)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := Response{
+		Nonce:              req.Nonce,
+		ID:                 activation.ID,
+		LicenseID:          lic.ID,
+		LicenseFingerprint: fingerprint,
+		Machine:            activation.Machine,
+		IssuedAt:           s.now().Unix(),
+		ExpiredAt:          lic.ExpiredAt,
+	}
+
+	return resp.Encode(s.opts.PrivateKey)
+}
@@ -0,0 +1,192 @@
+package activation
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/go-license/license"
+)
+
+func TestRequest_Encode_Decode(t *testing.T) {
+	_, machineKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	lic := &license.License{ID: "license-1"}
+
+	req, err := NewRequest(lic, "machine-1")
+	require.NoError(t, err)
+	assert.Equal(t, "license-1", req.LicenseID)
+	assert.NotEmpty(t, req.Nonce)
+
+	fingerprint, err := lic.GetFingerprint()
+	require.NoError(t, err)
+	assert.Equal(t, fingerprint, req.LicenseFingerprint)
+
+	encoded, err := req.Encode(machineKey)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), "-----BEGIN LICENSE ACTIVATION REQUEST-----")
+
+	t.Run("decode", func(t *testing.T) {
+		decoded, err := DecodeRequest(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, req, decoded)
+	})
+
+	t.Run("replaced machine key", func(t *testing.T) {
+		otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		block, _ := pem.Decode(encoded)
+		block.Headers[requestKeyHeader] = encodeKey(otherPublicKey)
+
+		_, err = DecodeRequest(pem.EncodeToMemory(block))
+		assert.ErrorIs(t, err, license.ErrVerifySignature)
+	})
+
+	t.Run("missing machine key", func(t *testing.T) {
+		block, _ := pem.Decode(encoded)
+		delete(block.Headers, requestKeyHeader)
+
+		_, err := DecodeRequest(pem.EncodeToMemory(block))
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("not a request", func(t *testing.T) {
+		_, err := DecodeRequest([]byte("not a request"))
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("machine required", func(t *testing.T) {
+		_, err := NewRequest(lic, "")
+		assert.ErrorIs(t, err, ErrMachineRequired)
+	})
+
+	t.Run("machine key required", func(t *testing.T) {
+		_, err := req.Encode(nil)
+		assert.ErrorIs(t, err, license.ErrPrivateKeyNotDefined)
+	})
+}
+
+func TestServer_RespondOffline(t *testing.T) {
+	ctx := context.Background()
+
+	_, machineKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	newRequest := func(t *testing.T, licenseKey []byte, verifier *license.Verifier, machine string) (*Request, []byte) {
+		t.Helper()
+
+		lic, err := verifier.Verify(licenseKey)
+		require.NoError(t, err)
+
+		req, err := NewRequest(lic, machine)
+		require.NoError(t, err)
+
+		encoded, err := req.Encode(machineKey)
+		require.NoError(t, err)
+
+		return req, encoded
+	}
+
+	t.Run("successful activation", func(t *testing.T) {
+		vendor := newTestVendor(t, 1)
+		licenseKey := vendor.issue(t, &license.License{
+			ID:        "license-1",
+			IssuedAt:  time.Now().Unix(),
+			ExpiredAt: time.Now().Add(time.Hour).Unix(),
+		})
+
+		req, encodedReq := newRequest(t, licenseKey, vendor.verifier, "machine-1")
+
+		encodedResp, err := vendor.srv.RespondOffline(ctx, encodedReq, licenseKey)
+		require.NoError(t, err)
+		assert.Contains(t, string(encodedResp), "-----BEGIN LICENSE ACTIVATION RESPONSE-----")
+
+		resp, err := DecodeResponse(encodedResp, vendor.verifier)
+		require.NoError(t, err)
+		require.NoError(t, resp.Check(req))
+
+		cert := resp.Certificate()
+		assert.NoError(t, cert.Check("license-1", "machine-1"))
+
+		tempFile, err := os.CreateTemp("", "activation_response_*.pem")
+		require.NoError(t, err)
+		defer os.Remove(tempFile.Name())
+
+		_, err = tempFile.Write(encodedResp)
+		require.NoError(t, err)
+		tempFile.Close()
+
+		stored, err := DecodeResponseFile(tempFile.Name(), vendor.verifier)
+		require.NoError(t, err)
+		assert.Equal(t, resp, stored)
+
+		_, err = DecodeResponseFile(tempFile.Name(), vendor.verifier.WithDecodeOptions(license.DecodeOptions{MaxInputBytes: 16}))
+		assert.ErrorIs(t, err, license.ErrLimitExceeded)
+
+		_, otherReq := newRequest(t, licenseKey, vendor.verifier, "machine-2")
+		_, err = vendor.srv.RespondOffline(ctx, otherReq, licenseKey)
+		assert.ErrorIs(t, err, ErrActivationLimit)
+	})
+
+	t.Run("response for another request", func(t *testing.T) {
+		vendor := newTestVendor(t, 0)
+		licenseKey := vendor.issue(t, &license.License{ID: "license-1"})
+
+		req, _ := newRequest(t, licenseKey, vendor.verifier, "machine-1")
+		_, encodedOther := newRequest(t, licenseKey, vendor.verifier, "machine-1")
+
+		encodedResp, err := vendor.srv.RespondOffline(ctx, encodedOther, licenseKey)
+		require.NoError(t, err)
+
+		resp, err := DecodeResponse(encodedResp, vendor.verifier)
+		require.NoError(t, err)
+		assert.ErrorIs(t, resp.Check(req), ErrNonceMismatch)
+	})
+
+	t.Run("response from another vendor", func(t *testing.T) {
+		vendor := newTestVendor(t, 0)
+		other := newTestVendor(t, 0)
+		licenseKey := other.issue(t, &license.License{ID: "license-1"})
+
+		_, encodedReq := newRequest(t, licenseKey, other.verifier, "machine-1")
+
+		encodedResp, err := other.srv.RespondOffline(ctx, encodedReq, licenseKey)
+		require.NoError(t, err)
+
+		_, err = DecodeResponse(encodedResp, vendor.verifier)
+		assert.ErrorIs(t, err, license.ErrVerifySignature)
+	})
+
+	t.Run("request for another license", func(t *testing.T) {
+		vendor := newTestVendor(t, 0)
+		licenseKey := vendor.issue(t, &license.License{ID: "license-1"})
+		otherKey := vendor.issue(t, &license.License{ID: "license-2"})
+
+		_, encodedReq := newRequest(t, otherKey, vendor.verifier, "machine-1")
+
+		_, err := vendor.srv.RespondOffline(ctx, encodedReq, licenseKey)
+		assert.ErrorIs(t, err, ErrLicenseMismatch)
+	})
+
+	t.Run("mismatching response", func(t *testing.T) {
+		req := &Request{Nonce: "n", LicenseID: "l", LicenseFingerprint: "f", Machine: "m"}
+
+		assert.NoError(t, (&Response{Nonce: "n", LicenseID: "l", LicenseFingerprint: "f", Machine: "m"}).Check(req))
+		assert.ErrorIs(t, (&Response{Nonce: "n", LicenseID: "x", LicenseFingerprint: "f", Machine: "m"}).Check(req), ErrLicenseMismatch)
+		assert.ErrorIs(t, (&Response{Nonce: "n", LicenseID: "l", LicenseFingerprint: "x", Machine: "m"}).Check(req), ErrLicenseMismatch)
+		assert.ErrorIs(t, (&Response{Nonce: "n", LicenseID: "l", LicenseFingerprint: "f", Machine: "x"}).Check(req), ErrMachineMismatch)
+	})
+}
+
+func encodeKey(publicKey ed25519.PublicKey) string {
+	return base64.RawURLEncoding.EncodeToString(publicKey)
+}
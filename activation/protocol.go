@@ -0,0 +1,69 @@
+package activation
+
+import (
+	"errors"
+	"net/http"
+)
+
+// HTTP endpoints served by Server.
+const (
+	ActivatePath   = "/activate"
+	DeactivatePath = "/deactivate"
+)
+
+// maxRequestBytes bounds activation request bodies.
+const maxRequestBytes = 1 << 20
+
+type activateRequest struct {
+	License string `json:"license"`
+	Machine string `json:"machine"`
+}
+
+type activateResponse struct {
+	Certificate string `json:"certificate"`
+}
+
+type errorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// errorCodes maps protocol errors to the codes sent over the wire.
+var errorCodes = map[error]string{
+	ErrInvalidRequest:     "invalid_request",
+	ErrInvalidLicense:     "invalid_license",
+	ErrLicenseExpired:     "license_expired",
+	ErrActivationLimit:    "activation_limit",
+	ErrActivationNotFound: "activation_not_found",
+	ErrMachineRequired:    "machine_required",
+}
+
+// errorStatus maps error codes to HTTP status codes.
+var errorStatus = map[string]int{
+	"invalid_request":      http.StatusBadRequest,
+	"machine_required":     http.StatusBadRequest,
+	"invalid_license":      http.StatusForbidden,
+	"license_expired":      http.StatusForbidden,
+	"activation_limit":     http.StatusConflict,
+	"activation_not_found": http.StatusNotFound,
+}
+
+func errorCode(err error) string {
+	for target, code := range errorCodes {
+		if errors.Is(err, target) {
+			return code
+		}
+	}
+
+	return "server_error"
+}
+
+func errorFromCode(code string) error {
+	for target, c := range errorCodes {
+		if c == code {
+			return target
+		}
+	}
+
+	return ErrServer
+}
@@ -0,0 +1,201 @@
+package activation
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/vitalvas/go-license/license"
+)
+
+// ServerOptions configures a Server.
+type ServerOptions struct {
+	// Verifier verifies the licenses submitted for activation.
+	Verifier *license.Verifier
+	// PrivateKey signs the activation certificates.
+	PrivateKey ed25519.PrivateKey
+	// Store keeps track of activations.
+	Store Store
+	// MaxActivations limits the number of machines a license can be activated on. Zero means no limit.
+	MaxActivations int
+	// Validate configures the validation of submitted licenses.
+	Validate license.ValidateOptions
+}
+
+// Server is the http.Handler of the activation protocol.
+type Server struct {
+	opts ServerOptions
+	mux  *http.ServeMux
+}
+
+// NewServer returns an activation Server.
+func NewServer(opts ServerOptions) (*Server, error) {
+	if opts.Verifier == nil {
+		return nil, license.ErrPublicKeyNotDefined
+	}
+
+	if opts.PrivateKey == nil {
+		return nil, license.ErrPrivateKeyNotDefined
+	}
+
+	if opts.Store == nil {
+		return nil, ErrStoreNotDefined
+	}
+
+	s := &Server{
+		opts: opts,
+		mux:  http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("POST "+ActivatePath, s.handleActivate)
+	s.mux.HandleFunc("POST "+DeactivatePath, s.handleDeactivate)
+
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleActivate(w http.ResponseWriter, r *http.Request) {
+	lic, req, err := s.readRequest(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	activation, err := s.activate(r.Context(), lic, req.Machine)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	cert := Certificate{
+		ID:        activation.ID,
+		LicenseID: activation.LicenseID,
+		Machine:   activation.Machine,
+		IssuedAt:  s.now().Unix(),
+		ExpiredAt: lic.ExpiredAt,
+	}
+
+	encoded, err := cert.Encode(s.opts.PrivateKey)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, activateResponse{Certificate: string(encoded)})
+}
+
+func (s *Server) handleDeactivate(w http.ResponseWriter, r *http.Request) {
+	lic, req, err := s.readRequest(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.opts.Store.Deactivate(r.Context(), lic.ID, req.Machine); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readRequest decodes the request body and checks the submitted license.
+func (s *Server) readRequest(w http.ResponseWriter, r *http.Request) (*license.License, *activateRequest, error) {
+	var req activateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		return nil, nil, ErrInvalidRequest
+	}
+
+	if len(req.Machine) == 0 {
+		return nil, nil, ErrMachineRequired
+	}
+
+	lic, err := s.checkLicense([]byte(req.License))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return lic, &req, nil
+}
+
+// checkLicense verifies and validates a license submitted for activation.
+func (s *Server) checkLicense(licenseKey []byte) (*license.License, error) {
+	lic, err := s.opts.Verifier.Verify(licenseKey)
+	if err != nil {
+		return nil, ErrInvalidLicense
+	}
+
+	if err := lic.Validate(s.opts.Validate); err != nil {
+		if errors.Is(err, license.ErrExpired) {
+			return nil, ErrLicenseExpired
+		}
+
+		return nil, ErrInvalidLicense
+	}
+
+	return lic, nil
+}
+
+// activate records the activation of the license on the machine.
+func (s *Server) activate(ctx context.Context, lic *license.License, machine string) (Activation, error) {
+	activationID, err := newActivationID()
+	if err != nil {
+		return Activation{}, err
+	}
+
+	return s.opts.Store.Activate(ctx, Activation{
+		ID:          activationID,
+		LicenseID:   lic.ID,
+		Machine:     machine,
+		ActivatedAt: s.now().UTC(),
+	}, s.opts.MaxActivations)
+}
+
+func (s *Server) now() time.Time {
+	if s.opts.Validate.Clock == nil {
+		return license.SystemClock.Now()
+	}
+
+	return s.opts.Validate.Clock.Now()
+}
+
+func newActivationID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	code := errorCode(err)
+
+	status, ok := errorStatus[code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	message := err.Error()
+	if !ok {
+		message = ErrServer.Error()
+	}
+
+	writeJSON(w, status, errorResponse{Error: code, Message: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,248 @@
+package activation
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/go-license/license"
+)
+
+type testVendor struct {
+	privateKey ed25519.PrivateKey
+	verifier   *license.Verifier
+	srv        *Server
+	server     *httptest.Server
+	client     *Client
+}
+
+func newTestVendor(t *testing.T, maxActivations int) *testVendor {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	verifier, err := license.NewVerifier(publicKey)
+	require.NoError(t, err)
+
+	srv, err := NewServer(ServerOptions{
+		Verifier:       verifier,
+		PrivateKey:     privateKey,
+		Store:          NewMemoryStore(),
+		MaxActivations: maxActivations,
+	})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(srv)
+	t.Cleanup(server.Close)
+
+	return &testVendor{
+		privateKey: privateKey,
+		verifier:   verifier,
+		srv:        srv,
+		server:     server,
+		client:     NewClient(server.URL, verifier, server.Client()),
+	}
+}
+
+func (v *testVendor) issue(t *testing.T, lic *license.License) []byte {
+	t.Helper()
+
+	encoded, err := lic.Encode(v.privateKey)
+	require.NoError(t, err)
+
+	return encoded
+}
+
+func TestNewServer_Errors(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	verifier, err := license.NewVerifier(publicKey)
+	require.NoError(t, err)
+
+	_, err = NewServer(ServerOptions{PrivateKey: privateKey, Store: NewMemoryStore()})
+	assert.ErrorIs(t, err, license.ErrPublicKeyNotDefined)
+
+	_, err = NewServer(ServerOptions{Verifier: verifier, Store: NewMemoryStore()})
+	assert.ErrorIs(t, err, license.ErrPrivateKeyNotDefined)
+
+	_, err = NewServer(ServerOptions{Verifier: verifier, PrivateKey: privateKey})
+	assert.ErrorIs(t, err, ErrStoreNotDefined)
+}
+
+func TestClient_Activate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successful activation", func(t *testing.T) {
+		vendor := newTestVendor(t, 1)
+
+		lic := &license.License{
+			ID:        "license-1",
+			IssuedAt:  time.Now().Unix(),
+			ExpiredAt: time.Now().Add(time.Hour).Unix(),
+		}
+		licenseKey := vendor.issue(t, lic)
+
+		cert, encoded, err := vendor.client.Activate(ctx, licenseKey, "machine-1")
+		require.NoError(t, err)
+		assert.Equal(t, "license-1", cert.LicenseID)
+		assert.Equal(t, "machine-1", cert.Machine)
+		assert.Equal(t, lic.ExpiredAt, cert.ExpiredAt)
+		assert.NotEmpty(t, cert.ID)
+
+		offline, err := DecodeCertificate(encoded, vendor.verifier)
+		require.NoError(t, err)
+		assert.NoError(t, offline.Check("license-1", "machine-1"))
+
+		again, _, err := vendor.client.Activate(ctx, licenseKey, "machine-1")
+		require.NoError(t, err)
+		assert.Equal(t, cert.ID, again.ID)
+	})
+
+	t.Run("activation limit", func(t *testing.T) {
+		vendor := newTestVendor(t, 1)
+		licenseKey := vendor.issue(t, &license.License{ID: "license-1"})
+
+		_, _, err := vendor.client.Activate(ctx, licenseKey, "machine-1")
+		require.NoError(t, err)
+
+		_, _, err = vendor.client.Activate(ctx, licenseKey, "machine-2")
+		assert.ErrorIs(t, err, ErrActivationLimit)
+
+		require.NoError(t, vendor.client.Deactivate(ctx, licenseKey, "machine-1"))
+
+		_, _, err = vendor.client.Activate(ctx, licenseKey, "machine-2")
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired license", func(t *testing.T) {
+		vendor := newTestVendor(t, 0)
+		licenseKey := vendor.issue(t, &license.License{
+			ID:        "license-1",
+			IssuedAt:  time.Now().Add(-2 * time.Hour).Unix(),
+			ExpiredAt: time.Now().Add(-time.Hour).Unix(),
+		})
+
+		_, _, err := vendor.client.Activate(ctx, licenseKey, "machine-1")
+		assert.ErrorIs(t, err, ErrLicenseExpired)
+	})
+
+	t.Run("license from another vendor", func(t *testing.T) {
+		vendor := newTestVendor(t, 0)
+		other := newTestVendor(t, 0)
+		licenseKey := other.issue(t, &license.License{ID: "license-1"})
+
+		client := NewClient(vendor.server.URL, other.verifier, vendor.server.Client())
+
+		_, _, err := client.Activate(ctx, licenseKey, "machine-1")
+		assert.ErrorIs(t, err, ErrInvalidLicense)
+	})
+
+	t.Run("client rejects untrusted license", func(t *testing.T) {
+		vendor := newTestVendor(t, 0)
+		other := newTestVendor(t, 0)
+		licenseKey := other.issue(t, &license.License{ID: "license-1"})
+
+		_, _, err := vendor.client.Activate(ctx, licenseKey, "machine-1")
+		assert.ErrorIs(t, err, license.ErrVerifySignature)
+	})
+
+	t.Run("machine required", func(t *testing.T) {
+		vendor := newTestVendor(t, 0)
+		licenseKey := vendor.issue(t, &license.License{ID: "license-1"})
+
+		_, _, err := vendor.client.Activate(ctx, licenseKey, "")
+		assert.ErrorIs(t, err, ErrMachineRequired)
+	})
+
+	t.Run("deactivate unknown activation", func(t *testing.T) {
+		vendor := newTestVendor(t, 0)
+		licenseKey := vendor.issue(t, &license.License{ID: "license-1"})
+
+		err := vendor.client.Deactivate(ctx, licenseKey, "machine-1")
+		assert.ErrorIs(t, err, ErrActivationNotFound)
+	})
+}
+
+func TestServer_Requests(t *testing.T) {
+	vendor := newTestVendor(t, 0)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{
+			name:   "malformed body",
+			method: http.MethodPost,
+			path:   ActivatePath,
+			body:   "not json",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "missing machine",
+			method: http.MethodPost,
+			path:   ActivatePath,
+			body:   `{"license":"x"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid license",
+			method: http.MethodPost,
+			path:   ActivatePath,
+			body:   `{"license":"x","machine":"m"}`,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "wrong method",
+			method: http.MethodGet,
+			path:   ActivatePath,
+			status: http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "unknown path",
+			method: http.MethodPost,
+			path:   "/unknown",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, vendor.server.URL+tt.path, bytes.NewBufferString(tt.body))
+			require.NoError(t, err)
+
+			resp, err := vendor.server.Client().Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestServer_RejectsCertificateAsLicense(t *testing.T) {
+	ctx := context.Background()
+	vendor := newTestVendor(t, 0)
+
+	licenseKey := vendor.issue(t, &license.License{ID: "license-1"})
+
+	_, encoded, err := vendor.client.Activate(ctx, licenseKey, "machine-1")
+	require.NoError(t, err)
+
+	_, _, err = vendor.client.Activate(ctx, encoded, "machine-2")
+	assert.ErrorIs(t, err, license.ErrMalformedLicense)
+
+	_, err = vendor.srv.checkLicense(encoded)
+	assert.ErrorIs(t, err, ErrInvalidLicense)
+}
@@ -0,0 +1,95 @@
+package activation
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Activation is a license activated on a machine.
+type Activation struct {
+	ID          string
+	LicenseID   string
+	Machine     string
+	ActivatedAt time.Time
+}
+
+// Store keeps track of activations.
+type Store interface {
+	// Activate records the activation of the license on the machine and returns the stored activation.
+	// Activating an already activated machine returns the existing activation. If limit is positive and
+	// the license is already activated on limit other machines, ErrActivationLimit is returned.
+	Activate(ctx context.Context, activation Activation, limit int) (Activation, error)
+	// Deactivate removes the activation of the license on the machine.
+	Deactivate(ctx context.Context, licenseID, machine string) error
+	// Count returns the number of machines the license is activated on.
+	Count(ctx context.Context, licenseID string) (int, error)
+}
+
+// MemoryStore is an in-memory Store. It is safe for concurrent use.
+type MemoryStore struct {
+	mu          sync.Mutex
+	activations map[string]map[string]Activation
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		activations: make(map[string]map[string]Activation),
+	}
+}
+
+// Activate implements Store.
+func (s *MemoryStore) Activate(_ context.Context, activation Activation, limit int) (Activation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	machines, ok := s.activations[activation.LicenseID]
+	if !ok {
+		machines = make(map[string]Activation)
+		s.activations[activation.LicenseID] = machines
+	}
+
+	if existing, ok := machines[activation.Machine]; ok {
+		return existing, nil
+	}
+
+	if limit > 0 && len(machines) >= limit {
+		return Activation{}, ErrActivationLimit
+	}
+
+	machines[activation.Machine] = activation
+
+	return activation, nil
+}
+
+// Deactivate implements Store.
+func (s *MemoryStore) Deactivate(_ context.Context, licenseID, machine string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	machines, ok := s.activations[licenseID]
+	if !ok {
+		return ErrActivationNotFound
+	}
+
+	if _, ok := machines[machine]; !ok {
+		return ErrActivationNotFound
+	}
+
+	delete(machines, machine)
+
+	if len(machines) == 0 {
+		delete(s.activations, licenseID)
+	}
+
+	return nil
+}
+
+// Count implements Store.
+func (s *MemoryStore) Count(_ context.Context, licenseID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.activations[licenseID]), nil
+}
@@ -0,0 +1,99 @@
+package activation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("activate and count", func(t *testing.T) {
+		store := NewMemoryStore()
+
+		activation, err := store.Activate(ctx, Activation{ID: "a1", LicenseID: "l1", Machine: "m1"}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, "a1", activation.ID)
+
+		count, err := store.Count(ctx, "l1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("reactivation returns existing activation", func(t *testing.T) {
+		store := NewMemoryStore()
+
+		_, err := store.Activate(ctx, Activation{ID: "a1", LicenseID: "l1", Machine: "m1"}, 1)
+		require.NoError(t, err)
+
+		activation, err := store.Activate(ctx, Activation{ID: "a2", LicenseID: "l1", Machine: "m1"}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "a1", activation.ID)
+	})
+
+	t.Run("limit reached", func(t *testing.T) {
+		store := NewMemoryStore()
+
+		_, err := store.Activate(ctx, Activation{ID: "a1", LicenseID: "l1", Machine: "m1"}, 1)
+		require.NoError(t, err)
+
+		_, err = store.Activate(ctx, Activation{ID: "a2", LicenseID: "l1", Machine: "m2"}, 1)
+		assert.ErrorIs(t, err, ErrActivationLimit)
+
+		_, err = store.Activate(ctx, Activation{ID: "a3", LicenseID: "l2", Machine: "m2"}, 1)
+		assert.NoError(t, err)
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		store := NewMemoryStore()
+
+		for i := range 10 {
+			_, err := store.Activate(ctx, Activation{LicenseID: "l1", Machine: fmt.Sprintf("m%d", i)}, 0)
+			require.NoError(t, err)
+		}
+
+		count, err := store.Count(ctx, "l1")
+		require.NoError(t, err)
+		assert.Equal(t, 10, count)
+	})
+
+	t.Run("deactivate", func(t *testing.T) {
+		store := NewMemoryStore()
+
+		_, err := store.Activate(ctx, Activation{LicenseID: "l1", Machine: "m1"}, 1)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Deactivate(ctx, "l1", "m1"))
+		assert.ErrorIs(t, store.Deactivate(ctx, "l1", "m1"), ErrActivationNotFound)
+		assert.ErrorIs(t, store.Deactivate(ctx, "l2", "m1"), ErrActivationNotFound)
+
+		_, err = store.Activate(ctx, Activation{LicenseID: "l1", Machine: "m2"}, 1)
+		assert.NoError(t, err)
+	})
+
+	t.Run("concurrent activations respect the limit", func(t *testing.T) {
+		store := NewMemoryStore()
+
+		var wg sync.WaitGroup
+
+		for i := range 20 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				store.Activate(ctx, Activation{LicenseID: "l1", Machine: fmt.Sprintf("m%d", i)}, 5)
+			}()
+		}
+
+		wg.Wait()
+
+		count, err := store.Count(ctx, "l1")
+		require.NoError(t, err)
+		assert.Equal(t, 5, count)
+	})
+}
@@ -5,6 +5,10 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/vitalvas/go-license/license"
@@ -19,7 +23,12 @@ func main() {
 		return
 	}
 
-	lic, err := license.DecodeFile(*filePath)
+	data, err := os.ReadFile(*filePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lic, err := license.DecodeUnverified(data)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -45,15 +54,39 @@ func main() {
 		fmt.Printf("License Issued At: %d (%s) \n", lic.IssuedAt, unixTimeUTC.Format("2006-01-02 15:04:05 Z07:00"))
 	}
 
+	if lic.NotBefore > 0 {
+		unixTimeUTC := time.Unix(lic.NotBefore, 0)
+		fmt.Printf("License Not Before: %d (%s) \n", lic.NotBefore, unixTimeUTC.Format("2006-01-02 15:04:05 Z07:00"))
+	}
+
 	if lic.ExpiredAt > 0 {
 		unixTimeUTC := time.Unix(lic.ExpiredAt, 0)
 		fmt.Printf("License Expires At: %d (%s) \n", lic.ExpiredAt, unixTimeUTC.Format("2006-01-02 15:04:05 Z07:00"))
 	}
 
-	if lic.Data != nil {
-		var data map[string]interface{}
+	if lic.GracePeriod > 0 {
+		fmt.Println("License Grace Period:", time.Duration(lic.GracePeriod)*time.Second)
+	}
+
+	if lic.Machine != nil {
+		fmt.Printf("License Machine Binding: %d components (threshold %d)\n", len(lic.Machine.Components), lic.Machine.Threshold)
+	}
+
+	if len(lic.Features) > 0 {
+		fmt.Println("License Features:", strings.Join(lic.Features, ", "))
+	}
 
-		if err := json.Unmarshal(lic.Data, &data); err != nil {
+	if len(lic.Limits) > 0 {
+		fmt.Println("License Limits:")
+
+		for _, name := range slices.Sorted(maps.Keys(lic.Limits)) {
+			fmt.Printf("  %s: %d\n", name, lic.Limits[name])
+		}
+	}
+
+	if lic.Data != nil {
+		data, err := license.DecodeData[map[string]interface{}](lic)
+		if err != nil {
 			log.Fatal(err)
 		}
 
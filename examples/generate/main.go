@@ -27,9 +27,7 @@ func main() {
 }
 
 type licenseContent struct {
-	Org      licenseContentOrg `json:"org"`
-	Features []string          `json:"features,omitempty"`
-	Limits   map[string]int    `json:"limits,omitempty"`
+	Org licenseContentOrg `json:"org"`
 }
 
 type licenseContentOrg struct {
@@ -44,6 +42,14 @@ func generate(privateKey ed25519.PrivateKey) []byte {
 		ID:        "e79ac885-de88-4c09-a2bc-7eca47a069bf",
 		IssuedAt:  now.Truncate(time.Hour * 24).UTC().Unix(),
 		ExpiredAt: now.Add(365 * 24 * time.Hour).Truncate(time.Hour * 24).UTC().Unix(),
+		Features: []string{
+			"api",
+			"api.auth.*",
+		},
+		Limits: map[string]int64{
+			"core.users.max":                 123,
+			"module.auth.ldap.directory.max": 1,
+		},
 	}
 
 	licData := licenseContent{
@@ -51,25 +57,14 @@ func generate(privateKey ed25519.PrivateKey) []byte {
 			Name:  "ACME inc",
 			Email: "acme@example.com",
 		},
-		Features: []string{
-			"api",
-			"api.auth.google",
-			"api.auth.ldap",
-		},
-		Limits: map[string]int{
-			"core.users.max":                 123,
-			"module.auth.ldap.directory.max": 1,
-		},
 	}
 
-	payload, err := json.Marshal(licData)
+	signed, err := license.NewWithData(lic, licData)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	lic.Data = payload
-
-	key, err := lic.Encode(privateKey)
+	key, err := signed.Encode(privateKey)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -85,7 +80,11 @@ func verify(licenseKey []byte, publicKey ed25519.PublicKey) {
 
 	fmt.Println(strings.Repeat("-", 32))
 
-	licData := lic.Data
+	data, err := license.DecodeDataWithOptions[licenseContent](lic, license.DataOptions{Strict: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	lic.Data = nil
 
 	encoder := json.NewEncoder(os.Stdout)
@@ -95,10 +94,12 @@ func verify(licenseKey []byte, publicKey ed25519.PublicKey) {
 
 	fmt.Println(strings.Repeat("-", 32))
 
-	var data licenseContent
-	if err := json.Unmarshal(licData, &data); err != nil {
-		log.Fatal(err)
-	}
-
 	encoder.Encode(data)
+
+	fmt.Println(strings.Repeat("-", 32))
+
+	maxUsers, _ := lic.Limit("core.users.max")
+
+	fmt.Println("api.auth.ldap:", lic.HasFeature("api.auth.ldap"))
+	fmt.Println("core.users.max:", maxUsers)
 }
@@ -0,0 +1,212 @@
+// Package fingerprint collects Linux machine identity signals for node-locked licenses.
+package fingerprint
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Component names.
+const (
+	MachineID   = "machine-id"
+	ProductUUID = "product-uuid"
+	MAC         = "mac"
+	CPUModel    = "cpu-model"
+	RootFSUUID  = "rootfs-uuid"
+)
+
+var ErrNoComponents = errors.New("no machine components collected")
+
+// Component is a hashed machine identity signal. Machines with several physical network
+// interfaces have a MAC component for each of them.
+type Component struct {
+	Name string
+	Hash string
+}
+
+// Fingerprint is the set of components collected from a machine.
+type Fingerprint struct {
+	Components []Component
+}
+
+// Hashes returns the component hashes, as listed in a license machine binding.
+func (f *Fingerprint) Hashes() []string {
+	hashes := make([]string, 0, len(f.Components))
+	for _, component := range f.Components {
+		hashes = append(hashes, component.Hash)
+	}
+
+	return hashes
+}
+
+// ID returns a single identifier over all components, e.g. for activation requests.
+func (f *Fingerprint) ID() string {
+	hashes := f.Hashes()
+	slices.Sort(hashes)
+
+	sum := sha256.Sum256([]byte(strings.Join(hashes, "\n")))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Collector collects machine components from a filesystem.
+type Collector struct {
+	// FS is the filesystem the signals are read from, rooted at "/". The host root filesystem is used if nil.
+	FS fs.FS
+}
+
+// Collect reads the machine components. Signals that are not available are skipped.
+func (c *Collector) Collect() (*Fingerprint, error) {
+	fsys := c.FS
+	if fsys == nil {
+		fsys = os.DirFS("/")
+	}
+
+	collectors := []struct {
+		name    string
+		collect func(fs.FS) []string
+	}{
+		{MachineID, single(collectMachineID)},
+		{ProductUUID, single(collectProductUUID)},
+		{MAC, collectMAC},
+		{CPUModel, single(collectCPUModel)},
+		{RootFSUUID, single(collectRootFSUUID)},
+	}
+
+	var fingerprint Fingerprint
+
+	for _, collector := range collectors {
+		for _, value := range collector.collect(fsys) {
+			if len(value) == 0 {
+				continue
+			}
+
+			fingerprint.Components = append(fingerprint.Components, Component{
+				Name: collector.name,
+				Hash: Hash(collector.name, value),
+			})
+		}
+	}
+
+	if len(fingerprint.Components) == 0 {
+		return nil, ErrNoComponents
+	}
+
+	return &fingerprint, nil
+}
+
+// Hash returns the hash of a component value.
+func Hash(name, value string) string {
+	sum := sha256.Sum256([]byte(name + ":" + value))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// single adapts a collector of one signal value.
+func single(collect func(fs.FS) string) func(fs.FS) []string {
+	return func(fsys fs.FS) []string {
+		return []string{collect(fsys)}
+	}
+}
+
+func readTrimmed(fsys fs.FS, name string) string {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+func collectMachineID(fsys fs.FS) string {
+	if id := readTrimmed(fsys, "etc/machine-id"); len(id) > 0 {
+		return id
+	}
+
+	return readTrimmed(fsys, "var/lib/dbus/machine-id")
+}
+
+func collectProductUUID(fsys fs.FS) string {
+	return strings.ToLower(readTrimmed(fsys, "sys/class/dmi/id/product_uuid"))
+}
+
+// collectMAC returns the sorted addresses of the physical network interfaces. Each address is
+// a component of its own, so replacing or adding a network card only changes its own entry.
+func collectMAC(fsys fs.FS) []string {
+	entries, err := fs.ReadDir(fsys, "sys/class/net")
+	if err != nil {
+		return nil
+	}
+
+	var addresses []string
+
+	for _, entry := range entries {
+		dir := path.Join("sys/class/net", entry.Name())
+
+		// virtual interfaces (lo, bridges, veth, tun) have no backing device
+		if _, err := fs.Stat(fsys, path.Join(dir, "device")); err != nil {
+			continue
+		}
+
+		address := strings.ToLower(readTrimmed(fsys, path.Join(dir, "address")))
+		if len(address) == 0 || address == "00:00:00:00:00:00" {
+			continue
+		}
+
+		addresses = append(addresses, address)
+	}
+
+	slices.Sort(addresses)
+
+	return slices.Compact(addresses)
+}
+
+func collectCPUModel(fsys fs.FS) string {
+	data, err := fs.ReadFile(fsys, "proc/cpuinfo")
+	if err != nil {
+		return ""
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if ok && strings.TrimSpace(key) == "model name" {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+// collectRootFSUUID returns the UUID of the root filesystem from /etc/fstab or the kernel command line.
+func collectRootFSUUID(fsys fs.FS) string {
+	if data, err := fs.ReadFile(fsys, "etc/fstab"); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || fields[1] != "/" {
+				continue
+			}
+
+			if uuid, ok := strings.CutPrefix(fields[0], "UUID="); ok {
+				return strings.ToLower(uuid)
+			}
+		}
+	}
+
+	for _, field := range strings.Fields(readTrimmed(fsys, "proc/cmdline")) {
+		if uuid, ok := strings.CutPrefix(field, "root=UUID="); ok {
+			return strings.ToLower(uuid)
+		}
+	}
+
+	return ""
+}
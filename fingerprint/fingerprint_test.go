@@ -0,0 +1,131 @@
+package fingerprint
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitalvas/go-license/license"
+)
+
+func newTestMachine() fstest.MapFS {
+	return fstest.MapFS{
+		"etc/machine-id":                      {Data: []byte("4c4c4544004a5a10804bb4c04f4e3432\n")},
+		"sys/class/dmi/id/product_uuid":       {Data: []byte("4C4C4544-004A-5A10-804B-B4C04F4E3432\n")},
+		"sys/class/net/lo/address":            {Data: []byte("00:00:00:00:00:00\n")},
+		"sys/class/net/eth0/address":          {Data: []byte("52:54:00:12:34:56\n")},
+		"sys/class/net/eth0/device/vendor":    {Data: []byte("0x8086\n")},
+		"sys/class/net/eth1/address":          {Data: []byte("52:54:00:ab:cd:ef\n")},
+		"sys/class/net/eth1/device/vendor":    {Data: []byte("0x8086\n")},
+		"sys/class/net/docker0/address":       {Data: []byte("02:42:ac:11:00:01\n")},
+		"proc/cpuinfo":                        {Data: []byte("processor\t: 0\nvendor_id\t: GenuineIntel\nmodel name\t: Intel(R) Xeon(R) CPU E5-2680 v4 @ 2.40GHz\n")},
+		"etc/fstab":                           {Data: []byte("# root\nUUID=0A1B2C3D-1111-2222-3333-444455556666 / ext4 defaults 0 1\nUUID=aaaa /boot ext4 defaults 0 2\n")},
+		"sys/class/net/eth0/device/subsystem": {Data: []byte("pci\n")},
+	}
+}
+
+func TestCollector_Collect(t *testing.T) {
+	t.Run("all components", func(t *testing.T) {
+		collector := Collector{FS: newTestMachine()}
+
+		fingerprint, err := collector.Collect()
+		require.NoError(t, err)
+		assert.Equal(t, []Component{
+			{Name: MachineID, Hash: Hash(MachineID, "4c4c4544004a5a10804bb4c04f4e3432")},
+			{Name: ProductUUID, Hash: Hash(ProductUUID, "4c4c4544-004a-5a10-804b-b4c04f4e3432")},
+			{Name: MAC, Hash: Hash(MAC, "52:54:00:12:34:56")},
+			{Name: MAC, Hash: Hash(MAC, "52:54:00:ab:cd:ef")},
+			{Name: CPUModel, Hash: Hash(CPUModel, "Intel(R) Xeon(R) CPU E5-2680 v4 @ 2.40GHz")},
+			{Name: RootFSUUID, Hash: Hash(RootFSUUID, "0a1b2c3d-1111-2222-3333-444455556666")},
+		}, fingerprint.Components)
+	})
+
+	t.Run("added network card", func(t *testing.T) {
+		machine := maps.Clone(newTestMachine())
+		machine["sys/class/net/eth2/address"] = &fstest.MapFile{Data: []byte("52:54:00:99:99:99\n")}
+		machine["sys/class/net/eth2/device/vendor"] = &fstest.MapFile{Data: []byte("0x8086\n")}
+
+		original, err := (&Collector{FS: newTestMachine()}).Collect()
+		require.NoError(t, err)
+
+		host, err := (&Collector{FS: machine}).Collect()
+		require.NoError(t, err)
+
+		assert.Subset(t, host.Hashes(), original.Hashes())
+		assert.Len(t, host.Hashes(), len(original.Hashes())+1)
+	})
+
+	t.Run("missing components are skipped", func(t *testing.T) {
+		collector := Collector{FS: fstest.MapFS{
+			"var/lib/dbus/machine-id": {Data: []byte("dbus-machine-id\n")},
+			"proc/cmdline":            {Data: []byte("BOOT_IMAGE=/vmlinuz root=UUID=ABCD ro quiet\n")},
+		}}
+
+		fingerprint, err := collector.Collect()
+		require.NoError(t, err)
+		assert.Equal(t, []Component{
+			{Name: MachineID, Hash: Hash(MachineID, "dbus-machine-id")},
+			{Name: RootFSUUID, Hash: Hash(RootFSUUID, "abcd")},
+		}, fingerprint.Components)
+	})
+
+	t.Run("no components", func(t *testing.T) {
+		collector := Collector{FS: fstest.MapFS{}}
+
+		_, err := collector.Collect()
+		assert.ErrorIs(t, err, ErrNoComponents)
+	})
+}
+
+func TestFingerprint_ID(t *testing.T) {
+	a := &Fingerprint{Components: []Component{{Name: "a", Hash: "1"}, {Name: "b", Hash: "2"}}}
+	b := &Fingerprint{Components: []Component{{Name: "b", Hash: "2"}, {Name: "a", Hash: "1"}}}
+	c := &Fingerprint{Components: []Component{{Name: "a", Hash: "1"}}}
+
+	assert.Equal(t, a.ID(), b.ID())
+	assert.NotEqual(t, a.ID(), c.ID())
+	assert.Equal(t, []string{"1", "2"}, a.Hashes())
+}
+
+func TestFingerprint_LicenseBinding(t *testing.T) {
+	original, err := (&Collector{FS: newTestMachine()}).Collect()
+	require.NoError(t, err)
+
+	lic := &license.License{
+		ID: "node-locked-license",
+		Machine: &license.MachineBinding{
+			Components: original.Hashes(),
+			Threshold:  5,
+		},
+	}
+
+	t.Run("same machine", func(t *testing.T) {
+		assert.NoError(t, lic.Validate(license.ValidateOptions{MachineComponents: original.Hashes()}))
+	})
+
+	t.Run("replaced network card", func(t *testing.T) {
+		machine := maps.Clone(newTestMachine())
+		machine["sys/class/net/eth1/address"] = &fstest.MapFile{Data: []byte("52:54:00:99:99:99\n")}
+
+		host, err := (&Collector{FS: machine}).Collect()
+		require.NoError(t, err)
+
+		assert.Len(t, slices.DeleteFunc(host.Hashes(), func(hash string) bool { return !slices.Contains(original.Hashes(), hash) }), 5)
+		assert.NoError(t, lic.Validate(license.ValidateOptions{MachineComponents: host.Hashes()}))
+	})
+
+	t.Run("cloned virtual machine", func(t *testing.T) {
+		machine := maps.Clone(newTestMachine())
+		machine["sys/class/dmi/id/product_uuid"] = &fstest.MapFile{Data: []byte("9f1c2d3e-0000-1111-2222-333344445555\n")}
+		machine["sys/class/net/eth0/address"] = &fstest.MapFile{Data: []byte("52:54:00:00:00:01\n")}
+		machine["sys/class/net/eth1/address"] = &fstest.MapFile{Data: []byte("52:54:00:00:00:02\n")}
+
+		host, err := (&Collector{FS: machine}).Collect()
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, lic.Validate(license.ValidateOptions{MachineComponents: host.Hashes()}), license.ErrMachineMismatch)
+	})
+}
@@ -0,0 +1,202 @@
+//go:build go1.27
+
+// Package hybrid adds the hybrid post-quantum signature algorithm "ed25519-mldsa65" to the license package.
+// A hybrid signature is an Ed25519 signature and an ML-DSA-65 (FIPS 204) signature of the same message,
+// and it is only valid if both are. Licenses stay unforgeable as long as one of the two algorithms holds.
+//
+// Importing the package registers the algorithm:
+//
+//	import _ "github.com/vitalvas/go-license/hybrid"
+package hybrid
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/mldsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/vitalvas/go-license/license"
+)
+
+// Name is the algorithm name recorded in the envelope "algorithm" header.
+const Name = "ed25519-mldsa65"
+
+// SignatureSize is the size of a hybrid signature.
+const SignatureSize = ed25519.SignatureSize + mldsa.MLDSA65SignatureSize
+
+// domain prefixes the signed message, so neither component signature is valid on its own
+// as a signature of the message.
+const domain = "go-license " + Name + "\x00"
+
+// Algorithm is the hybrid Ed25519 and ML-DSA-65 signature algorithm, with *PublicKey keys.
+var Algorithm license.Algorithm = algorithm{}
+
+var ErrInvalidSigner = errors.New("hybrid signer must have an ed25519 and an ML-DSA-65 key")
+
+func init() {
+	license.RegisterAlgorithm(Algorithm)
+}
+
+// PublicKey is a hybrid public key.
+type PublicKey struct {
+	Ed25519 ed25519.PublicKey
+	MLDSA   *mldsa.PublicKey
+}
+
+// NewPublicKey decodes a hybrid public key encoded with PublicKey.Bytes.
+func NewPublicKey(data []byte) (*PublicKey, error) {
+	if len(data) != ed25519.PublicKeySize+mldsa.MLDSA65PublicKeySize {
+		return nil, license.ErrInvalidPublicKey
+	}
+
+	mldsaKey, err := mldsa.NewPublicKey(mldsa.MLDSA65(), data[ed25519.PublicKeySize:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", license.ErrInvalidPublicKey, err)
+	}
+
+	return &PublicKey{
+		Ed25519: bytes.Clone(data[:ed25519.PublicKeySize]),
+		MLDSA:   mldsaKey,
+	}, nil
+}
+
+// Bytes returns the encoding of the public key: the Ed25519 key followed by the ML-DSA-65 key.
+func (pk *PublicKey) Bytes() []byte {
+	return append(bytes.Clone(pk.Ed25519), pk.MLDSA.Bytes()...)
+}
+
+// valid reports whether both component keys are set and the ML-DSA key is an ML-DSA-65 key.
+func (pk *PublicKey) valid() bool {
+	return pk != nil && len(pk.Ed25519) == ed25519.PublicKeySize && pk.MLDSA != nil && pk.MLDSA.Parameters() == mldsa.MLDSA65()
+}
+
+// Signer signs with both component signers and implements crypto.Signer, so it can be passed to
+// License.EncodeWithSigner. Either component can be backed by an HSM or a KMS.
+type Signer struct {
+	Ed25519 crypto.Signer // with an ed25519.PublicKey
+	MLDSA   crypto.Signer // with an ML-DSA-65 *mldsa.PublicKey
+}
+
+// GenerateKey generates a hybrid signer with new in-memory Ed25519 and ML-DSA-65 keys.
+func GenerateKey() (*Signer, error) {
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	mldsaKey, err := mldsa.GenerateKey(mldsa.MLDSA65())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{
+		Ed25519: ed25519Key,
+		MLDSA:   mldsaKey,
+	}, nil
+}
+
+// Public returns the hybrid *PublicKey of the signer, or nil if a component key is missing or of the wrong type.
+func (s *Signer) Public() crypto.PublicKey {
+	pk, err := s.publicKey()
+	if err != nil {
+		return nil
+	}
+
+	return pk
+}
+
+// Sign signs the message with both component signers and returns the Ed25519 signature followed by
+// the ML-DSA-65 signature. The message is signed directly, so opts must not specify a hash function.
+func (s *Signer) Sign(random io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts != nil && opts.HashFunc() != 0 {
+		return nil, fmt.Errorf("hybrid: unsupported hash function %v", opts.HashFunc())
+	}
+
+	if _, err := s.publicKey(); err != nil {
+		return nil, err
+	}
+
+	message = domainMessage(message)
+
+	ed25519Signature, err := s.Ed25519.Sign(random, message, crypto.Hash(0))
+	if err != nil {
+		return nil, err
+	}
+
+	mldsaSignature, err := s.MLDSA.Sign(random, message, crypto.Hash(0))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ed25519Signature) != ed25519.SignatureSize || len(mldsaSignature) != mldsa.MLDSA65SignatureSize {
+		return nil, errors.New("hybrid: invalid component signature size")
+	}
+
+	return append(ed25519Signature, mldsaSignature...), nil
+}
+
+func (s *Signer) publicKey() (*PublicKey, error) {
+	if s == nil || s.Ed25519 == nil || s.MLDSA == nil {
+		return nil, ErrInvalidSigner
+	}
+
+	ed25519Key, ok := s.Ed25519.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, ErrInvalidSigner
+	}
+
+	mldsaKey, ok := s.MLDSA.Public().(*mldsa.PublicKey)
+	if !ok {
+		return nil, ErrInvalidSigner
+	}
+
+	pk := &PublicKey{Ed25519: ed25519Key, MLDSA: mldsaKey}
+	if !pk.valid() {
+		return nil, ErrInvalidSigner
+	}
+
+	return pk, nil
+}
+
+// domainMessage returns the message prefixed with the domain of the algorithm.
+func domainMessage(message []byte) []byte {
+	return append([]byte(domain), message...)
+}
+
+type algorithm struct{}
+
+func (algorithm) Name() string {
+	return Name
+}
+
+func (algorithm) MarshalPublicKey(publicKey crypto.PublicKey) ([]byte, error) {
+	pk, ok := publicKey.(*PublicKey)
+	if !ok || !pk.valid() {
+		return nil, license.ErrInvalidPublicKey
+	}
+
+	return pk.Bytes(), nil
+}
+
+func (algorithm) Sign(signer crypto.Signer, message []byte) ([]byte, error) {
+	return signer.Sign(rand.Reader, message, crypto.Hash(0))
+}
+
+func (algorithm) Verify(publicKey crypto.PublicKey, message, signature []byte) bool {
+	pk, ok := publicKey.(*PublicKey)
+	if !ok || !pk.valid() || len(signature) != SignatureSize {
+		return false
+	}
+
+	message = domainMessage(message)
+
+	if !ed25519.Verify(pk.Ed25519, message, signature[:ed25519.SignatureSize]) {
+		return false
+	}
+
+	return mldsa.Verify(pk.MLDSA, message, signature[ed25519.SignatureSize:], nil) == nil
+}
@@ -0,0 +1,177 @@
+//go:build go1.27
+
+package hybrid
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/mldsa"
+	"crypto/rand"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitalvas/go-license/license"
+)
+
+func TestAlgorithm(t *testing.T) {
+	signer, err := GenerateKey()
+	require.NoError(t, err)
+
+	publicKey, ok := signer.Public().(*PublicKey)
+	require.True(t, ok)
+
+	message := []byte("license payload")
+
+	signature, err := Algorithm.Sign(signer, message)
+	require.NoError(t, err)
+	assert.Len(t, signature, SignatureSize)
+
+	t.Run("registered", func(t *testing.T) {
+		alg, ok := license.LookupAlgorithm(Name)
+		require.True(t, ok)
+		assert.Equal(t, Algorithm, alg)
+	})
+
+	t.Run("valid signature", func(t *testing.T) {
+		assert.True(t, Algorithm.Verify(publicKey, message, signature))
+		assert.False(t, Algorithm.Verify(publicKey, []byte("other payload"), signature))
+	})
+
+	t.Run("both components are required", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			index int
+		}{
+			{name: "ed25519 component", index: 0},
+			{name: "ML-DSA component", index: ed25519.SignatureSize},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tampered := append([]byte(nil), signature...)
+				tampered[tt.index] ^= 0xff
+
+				assert.False(t, Algorithm.Verify(publicKey, message, tampered))
+			})
+		}
+
+		assert.False(t, Algorithm.Verify(publicKey, message, signature[:ed25519.SignatureSize]))
+	})
+
+	t.Run("components are not valid alone", func(t *testing.T) {
+		assert.False(t, ed25519.Verify(publicKey.Ed25519, message, signature[:ed25519.SignatureSize]))
+		assert.Error(t, mldsa.Verify(publicKey.MLDSA, message, signature[ed25519.SignatureSize:], nil))
+	})
+
+	t.Run("public key encoding", func(t *testing.T) {
+		encoded, err := Algorithm.MarshalPublicKey(publicKey)
+		require.NoError(t, err)
+		assert.Len(t, encoded, ed25519.PublicKeySize+mldsa.MLDSA65PublicKeySize)
+
+		decoded, err := NewPublicKey(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, publicKey.Bytes(), decoded.Bytes())
+		assert.True(t, Algorithm.Verify(decoded, message, signature))
+
+		_, err = NewPublicKey(encoded[1:])
+		assert.ErrorIs(t, err, license.ErrInvalidPublicKey)
+	})
+
+	t.Run("invalid public keys", func(t *testing.T) {
+		mldsa44Key, err := mldsa.GenerateKey(mldsa.MLDSA44())
+		require.NoError(t, err)
+
+		tests := []struct {
+			name      string
+			publicKey crypto.PublicKey
+		}{
+			{name: "ed25519 key", publicKey: publicKey.Ed25519},
+			{name: "nil key", publicKey: (*PublicKey)(nil)},
+			{name: "missing ML-DSA key", publicKey: &PublicKey{Ed25519: publicKey.Ed25519}},
+			{name: "ML-DSA-44 key", publicKey: &PublicKey{Ed25519: publicKey.Ed25519, MLDSA: mldsa44Key.PublicKey()}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := Algorithm.MarshalPublicKey(tt.publicKey)
+				assert.ErrorIs(t, err, license.ErrInvalidPublicKey)
+				assert.False(t, Algorithm.Verify(tt.publicKey, message, signature))
+			})
+		}
+	})
+
+	t.Run("invalid signer", func(t *testing.T) {
+		_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		_, err = (&Signer{Ed25519: ed25519Key}).Sign(rand.Reader, message, nil)
+		assert.ErrorIs(t, err, ErrInvalidSigner)
+
+		_, err = (&Signer{Ed25519: ed25519Key, MLDSA: ed25519Key}).Sign(rand.Reader, message, nil)
+		assert.ErrorIs(t, err, ErrInvalidSigner)
+
+		_, err = signer.Sign(rand.Reader, message, crypto.SHA256)
+		assert.Error(t, err)
+
+		assert.Nil(t, (&Signer{}).Public())
+	})
+}
+
+func TestLicense(t *testing.T) {
+	signer, err := GenerateKey()
+	require.NoError(t, err)
+
+	lic := &license.License{
+		ID:        "hybrid-license",
+		IssuedAt:  time.Now().Unix(),
+		ExpiredAt: time.Now().AddDate(10, 0, 0).Unix(),
+	}
+
+	encoded, err := lic.EncodeWithSigner(signer)
+	require.NoError(t, err)
+
+	block, _ := pem.Decode(encoded)
+	require.NotNil(t, block)
+	assert.Equal(t, Name, block.Headers["algorithm"])
+
+	t.Run("hybrid key", func(t *testing.T) {
+		keyring := license.NewKeyring()
+		keyID, err := keyring.AddKey(Algorithm, signer.Public(), license.KeyActive)
+		require.NoError(t, err)
+
+		verifier, err := license.NewKeyringVerifier(keyring)
+		require.NoError(t, err)
+
+		decoded, err := verifier.Verify(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, lic.ID, decoded.ID)
+		assert.Equal(t, keyID, decoded.SignedBy())
+	})
+
+	t.Run("ed25519 component key", func(t *testing.T) {
+		publicKey := signer.Public().(*PublicKey)
+
+		_, err := license.Decode(encoded, publicKey.Ed25519)
+		assert.ErrorIs(t, err, license.ErrUnknownKey)
+	})
+
+	t.Run("downgraded algorithm header", func(t *testing.T) {
+		keyring := license.NewKeyring()
+		_, err := keyring.AddKey(Algorithm, signer.Public(), license.KeyActive)
+		require.NoError(t, err)
+
+		downgraded := *block
+		downgraded.Headers = map[string]string{}
+		for key, value := range block.Headers {
+			downgraded.Headers[key] = value
+		}
+		downgraded.Headers["algorithm"] = license.AlgorithmEd25519.Name()
+
+		_, err = keyring.Decode(pem.EncodeToMemory(&downgraded))
+		assert.ErrorIs(t, err, license.ErrAlgorithmMismatch)
+	})
+}
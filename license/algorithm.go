@@ -0,0 +1,251 @@
+package license
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// Algorithm signs and verifies license signatures with one type of key. Its name is recorded
+// in the envelope, and a key registered in a Keyring only verifies signatures of its algorithm.
+type Algorithm interface {
+	// Name returns the identifier recorded in the envelope "algorithm" header.
+	Name() string
+	// MarshalPublicKey returns the encoding of the public key that its key ID is derived from.
+	// It returns an error wrapping ErrInvalidPublicKey if the key is not a key of the algorithm.
+	MarshalPublicKey(publicKey crypto.PublicKey) ([]byte, error)
+	// Sign signs the message with the signer, whose public key is a key of the algorithm.
+	Sign(signer crypto.Signer, message []byte) ([]byte, error)
+	// Verify reports whether the signature of the message is valid for the public key.
+	Verify(publicKey crypto.PublicKey, message, signature []byte) bool
+}
+
+// Built-in signature algorithms.
+var (
+	// AlgorithmEd25519 is Ed25519 with ed25519.PublicKey keys.
+	AlgorithmEd25519 Algorithm = ed25519Algorithm{}
+	// AlgorithmES256 is ECDSA with the P-256 curve and SHA-256, with *ecdsa.PublicKey keys.
+	// Signatures are the fixed-size concatenation of r and s, as in JOSE and COSE.
+	AlgorithmES256 Algorithm = es256Algorithm{}
+	// AlgorithmPS256 is RSASSA-PSS with SHA-256 and a salt of the hash size, with *rsa.PublicKey
+	// keys of at least 2048 bits.
+	AlgorithmPS256 Algorithm = ps256Algorithm{}
+)
+
+var (
+	algorithmsMu sync.RWMutex
+	algorithms   = []Algorithm{AlgorithmEd25519, AlgorithmES256, AlgorithmPS256}
+)
+
+// RegisterAlgorithm makes a signature algorithm available to encode and decode licenses.
+// It is meant to be called from an init function and panics if the algorithm is nil or
+// an algorithm with the same name is already registered.
+func RegisterAlgorithm(alg Algorithm) {
+	if alg == nil {
+		panic("license: RegisterAlgorithm algorithm is nil")
+	}
+
+	algorithmsMu.Lock()
+	defer algorithmsMu.Unlock()
+
+	for _, registered := range algorithms {
+		if registered.Name() == alg.Name() {
+			panic("license: RegisterAlgorithm called twice for algorithm " + alg.Name())
+		}
+	}
+
+	algorithms = append(algorithms, alg)
+}
+
+// LookupAlgorithm returns the registered algorithm with the given name.
+func LookupAlgorithm(name string) (Algorithm, bool) {
+	algorithmsMu.RLock()
+	defer algorithmsMu.RUnlock()
+
+	for _, alg := range algorithms {
+		if alg.Name() == name {
+			return alg, true
+		}
+	}
+
+	return nil, false
+}
+
+// algorithmOf returns the first registered algorithm the public key is a key of.
+func algorithmOf(publicKey crypto.PublicKey) (Algorithm, error) {
+	algorithmsMu.RLock()
+	defer algorithmsMu.RUnlock()
+
+	for _, alg := range algorithms {
+		if _, err := alg.MarshalPublicKey(publicKey); err == nil {
+			return alg, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedAlgorithm, publicKey)
+}
+
+// algorithmKeyID returns the ID of the public key of the algorithm.
+func algorithmKeyID(alg Algorithm, publicKey crypto.PublicKey) (string, error) {
+	encoded, err := alg.MarshalPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	return keyIDFromBytes(encoded), nil
+}
+
+type ed25519Algorithm struct{}
+
+func (ed25519Algorithm) Name() string {
+	return "ed25519"
+}
+
+func (ed25519Algorithm) MarshalPublicKey(publicKey crypto.PublicKey) ([]byte, error) {
+	key, ok := publicKey.(ed25519.PublicKey)
+	if !ok || len(key) != ed25519.PublicKeySize {
+		return nil, ErrInvalidPublicKey
+	}
+
+	return bytes.Clone(key), nil
+}
+
+func (ed25519Algorithm) Sign(signer crypto.Signer, message []byte) ([]byte, error) {
+	return signer.Sign(rand.Reader, message, crypto.Hash(0))
+}
+
+func (ed25519Algorithm) Verify(publicKey crypto.PublicKey, message, signature []byte) bool {
+	key, ok := publicKey.(ed25519.PublicKey)
+	if !ok || len(key) != ed25519.PublicKeySize {
+		return false
+	}
+
+	return verifySignature(message, signature, []ed25519.PublicKey{key})
+}
+
+type es256Algorithm struct{}
+
+// es256ScalarSize is the size of r and s in an ES256 signature.
+const es256ScalarSize = 32
+
+// ecdsaSignature is the ASN.1 structure of an ECDSA signature.
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+func (es256Algorithm) Name() string {
+	return "es256"
+}
+
+func (es256Algorithm) MarshalPublicKey(publicKey crypto.PublicKey) ([]byte, error) {
+	key, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok || key.Curve != elliptic.P256() {
+		return nil, ErrInvalidPublicKey
+	}
+
+	encoded, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPublicKey, err)
+	}
+
+	return encoded, nil
+}
+
+func (es256Algorithm) Sign(signer crypto.Signer, message []byte) ([]byte, error) {
+	digest := sha256.Sum256(message)
+
+	der, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	var sig ecdsaSignature
+	if rest, err := asn1.Unmarshal(der, &sig); err != nil || len(rest) > 0 {
+		return nil, errors.New("invalid ECDSA signature encoding")
+	}
+
+	if sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || sig.R.BitLen() > 8*es256ScalarSize || sig.S.BitLen() > 8*es256ScalarSize {
+		return nil, errors.New("invalid ECDSA signature")
+	}
+
+	signature := make([]byte, 2*es256ScalarSize)
+	sig.R.FillBytes(signature[:es256ScalarSize])
+	sig.S.FillBytes(signature[es256ScalarSize:])
+
+	return signature, nil
+}
+
+func (es256Algorithm) Verify(publicKey crypto.PublicKey, message, signature []byte) bool {
+	key, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok || key.Curve != elliptic.P256() || len(signature) != 2*es256ScalarSize {
+		return false
+	}
+
+	der, err := asn1.Marshal(ecdsaSignature{
+		R: new(big.Int).SetBytes(signature[:es256ScalarSize]),
+		S: new(big.Int).SetBytes(signature[es256ScalarSize:]),
+	})
+	if err != nil {
+		return false
+	}
+
+	digest := sha256.Sum256(message)
+
+	return ecdsa.VerifyASN1(key, digest[:], der)
+}
+
+type ps256Algorithm struct{}
+
+// minRSAKeyBits is the minimum size of PS256 keys.
+const minRSAKeyBits = 2048
+
+var ps256Options = &rsa.PSSOptions{
+	SaltLength: rsa.PSSSaltLengthEqualsHash,
+	Hash:       crypto.SHA256,
+}
+
+func (ps256Algorithm) Name() string {
+	return "ps256"
+}
+
+func (ps256Algorithm) MarshalPublicKey(publicKey crypto.PublicKey) ([]byte, error) {
+	key, ok := publicKey.(*rsa.PublicKey)
+	if !ok || key.N == nil || key.N.BitLen() < minRSAKeyBits {
+		return nil, ErrInvalidPublicKey
+	}
+
+	encoded, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPublicKey, err)
+	}
+
+	return encoded, nil
+}
+
+func (ps256Algorithm) Sign(signer crypto.Signer, message []byte) ([]byte, error) {
+	digest := sha256.Sum256(message)
+
+	return signer.Sign(rand.Reader, digest[:], ps256Options)
+}
+
+func (ps256Algorithm) Verify(publicKey crypto.PublicKey, message, signature []byte) bool {
+	key, ok := publicKey.(*rsa.PublicKey)
+	if !ok || key.N == nil || key.N.BitLen() < minRSAKeyBits {
+		return false
+	}
+
+	digest := sha256.Sum256(message)
+
+	return rsa.VerifyPSS(key, crypto.SHA256, digest[:], signature, ps256Options) == nil
+}
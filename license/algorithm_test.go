@@ -0,0 +1,186 @@
+package license
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlgorithms(t *testing.T) {
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		alg           Algorithm
+		signer        crypto.Signer
+		signatureSize int
+	}{
+		{name: "ed25519", alg: AlgorithmEd25519, signer: ed25519Key, signatureSize: ed25519.SignatureSize},
+		{name: "es256", alg: AlgorithmES256, signer: ecdsaKey, signatureSize: 64},
+		{name: "ps256", alg: AlgorithmPS256, signer: rsaKey, signatureSize: 256},
+	}
+
+	license := &License{ID: "algorithm-license", Features: []string{"reports"}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.name, tt.alg.Name())
+
+			found, ok := LookupAlgorithm(tt.name)
+			require.True(t, ok)
+			assert.Equal(t, tt.alg, found)
+
+			signature, err := tt.alg.Sign(tt.signer, []byte("message"))
+			require.NoError(t, err)
+			assert.Len(t, signature, tt.signatureSize)
+			assert.True(t, tt.alg.Verify(tt.signer.Public(), []byte("message"), signature))
+			assert.False(t, tt.alg.Verify(tt.signer.Public(), []byte("other message"), signature))
+			assert.False(t, tt.alg.Verify(tt.signer.Public(), []byte("message"), signature[1:]))
+
+			encoded, err := license.EncodeWithSigner(tt.signer)
+			require.NoError(t, err)
+
+			block, _ := pem.Decode(encoded)
+			require.NotNil(t, block)
+			assert.Equal(t, tt.name, block.Headers["algorithm"])
+
+			keyring := NewKeyring()
+			keyID, err := keyring.AddKey(tt.alg, tt.signer.Public(), KeyActive)
+			require.NoError(t, err)
+
+			verifier, err := NewKeyringVerifier(keyring)
+			require.NoError(t, err)
+
+			decoded, err := verifier.Verify(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, license.ID, decoded.ID)
+			assert.Equal(t, keyID, decoded.SignedBy())
+		})
+	}
+
+	t.Run("key of another algorithm", func(t *testing.T) {
+		_, err := NewKeyring().AddKey(AlgorithmPS256, ecdsaKey.Public(), KeyActive)
+		assert.ErrorIs(t, err, ErrInvalidPublicKey)
+
+		_, err = NewKeyring().AddKey(AlgorithmES256, ed25519Key.Public(), KeyActive)
+		assert.ErrorIs(t, err, ErrInvalidPublicKey)
+
+		_, err = NewKeyring().AddKey(nil, ed25519Key.Public(), KeyActive)
+		assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+	})
+
+	t.Run("unsupported keys", func(t *testing.T) {
+		p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		_, err = license.EncodeWithSigner(p384Key)
+		assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+		smallRSAKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+
+		_, err = license.EncodeWithSigner(smallRSAKey)
+		assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+		_, err = NewKeyring().AddKey(AlgorithmPS256, smallRSAKey.Public(), KeyActive)
+		assert.ErrorIs(t, err, ErrInvalidPublicKey)
+	})
+
+	t.Run("algorithm confusion", func(t *testing.T) {
+		encoded, err := license.EncodeWithSigner(ecdsaKey)
+		require.NoError(t, err)
+
+		keyring := NewKeyring()
+		_, err = keyring.AddKey(AlgorithmES256, ecdsaKey.Public(), KeyActive)
+		require.NoError(t, err)
+
+		block, _ := pem.Decode(encoded)
+		require.NotNil(t, block)
+
+		for _, name := range []string{"ed25519", "ps256"} {
+			block.Headers["algorithm"] = name
+
+			_, err = keyring.Decode(pem.EncodeToMemory(block))
+			assert.ErrorIs(t, err, ErrAlgorithmMismatch)
+			assert.ErrorIs(t, err, ErrVerifySignature)
+		}
+	})
+
+	t.Run("ed25519 keys reject other algorithms", func(t *testing.T) {
+		encoded, err := license.EncodeWithSigner(rsaKey)
+		require.NoError(t, err)
+
+		_, err = Decode(encoded, ed25519Key.Public().(ed25519.PublicKey))
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("same key with another algorithm", func(t *testing.T) {
+		keyring := NewKeyring()
+		_, err := keyring.AddKey(AlgorithmES256, ecdsaKey.Public(), KeyActive)
+		require.NoError(t, err)
+
+		_, err = keyring.AddKey(sameKeyAlgorithm{AlgorithmES256}, ecdsaKey.Public(), KeyRetired)
+		assert.ErrorIs(t, err, ErrAlgorithmMismatch)
+	})
+
+	t.Run("co-signers of other algorithms", func(t *testing.T) {
+		encoded, err := license.EncodeWithSignerOptions(ed25519Key, EncodeOptions{
+			CoSigners: []crypto.Signer{ecdsaKey, rsaKey},
+		})
+		require.NoError(t, err)
+
+		keyring := NewKeyring()
+		_, err = keyring.Add(ed25519Key.Public().(ed25519.PublicKey), KeyActive)
+		require.NoError(t, err)
+		_, err = keyring.AddKey(AlgorithmES256, ecdsaKey.Public(), KeyActive)
+		require.NoError(t, err)
+		_, err = keyring.AddKey(AlgorithmPS256, rsaKey.Public(), KeyActive)
+		require.NoError(t, err)
+
+		verifier, err := NewKeyringVerifier(keyring)
+		require.NoError(t, err)
+
+		decoded, err := verifier.WithThreshold(3).Verify(encoded)
+		require.NoError(t, err)
+		assert.Len(t, decoded.Signers(), 3)
+	})
+}
+
+// sameKeyAlgorithm is an algorithm with the keys of another algorithm under a different name.
+type sameKeyAlgorithm struct {
+	Algorithm
+}
+
+func (sameKeyAlgorithm) Name() string {
+	return "same-key"
+}
+
+func TestRegisterAlgorithm(t *testing.T) {
+	t.Run("duplicate name", func(t *testing.T) {
+		assert.Panics(t, func() { RegisterAlgorithm(AlgorithmEd25519) })
+	})
+
+	t.Run("nil algorithm", func(t *testing.T) {
+		assert.Panics(t, func() { RegisterAlgorithm(nil) })
+	})
+
+	t.Run("unknown algorithm", func(t *testing.T) {
+		_, ok := LookupAlgorithm("rsa")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,178 @@
+package license
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
+	"unicode/utf16"
+)
+
+// canonicalJSON returns the JSON Canonicalization Scheme (RFC 8785) serialization of v:
+// object members sorted by their UTF-16 code units, no insignificant whitespace,
+// minimal string escaping and numbers formatted as IEEE 754 doubles.
+// Unlike RFC 8785, every number with an integral value is written exactly as plain digits,
+// however it is spelled (10, 1e1 or 10.0), so int64 claims such as Limits above 2^53 do not
+// collide and Data re-marshaled through float64 keeps its fingerprint. The output differs from
+// RFC 8785 for integers a double can not hold and for integers of 10^21 and above.
+func canonicalJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, value); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+
+	case json.Number:
+		return writeCanonicalNumber(buf, v)
+
+	case string:
+		writeCanonicalString(buf, v)
+
+	case []any:
+		buf.WriteByte('[')
+
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := writeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte(']')
+
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+
+		slices.SortFunc(keys, func(a, b string) int {
+			return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+		})
+
+		buf.WriteByte('{')
+
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			writeCanonicalString(buf, key)
+			buf.WriteByte(':')
+
+			if err := writeCanonical(buf, v[key]); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte('}')
+
+	default:
+		return fmt.Errorf("unsupported canonical JSON type %T", value)
+	}
+
+	return nil
+}
+
+// writeCanonicalNumber writes numbers with an integral value exactly and other numbers in the ECMAScript
+// Number.prototype.toString format, which encoding/json uses for float64 values apart from negative zero.
+func writeCanonicalNumber(buf *bytes.Buffer, number json.Number) error {
+	f, err := strconv.ParseFloat(number.String(), 64)
+	if err != nil {
+		return err
+	}
+
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Errorf("unsupported canonical JSON number %s", number)
+	}
+
+	if f == 0 {
+		buf.WriteByte('0')
+		return nil
+	}
+
+	// Only a number whose double is integral can have an integral value, which also bounds the
+	// exponent the exact value is computed with.
+	if f == math.Trunc(f) {
+		if r, ok := new(big.Rat).SetString(number.String()); ok && r.IsInt() {
+			buf.WriteString(r.Num().String())
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	buf.Write(data)
+
+	return nil
+}
+
+// writeCanonicalString writes the string escaping only quotes, backslashes and control characters.
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+
+	buf.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[r>>4])
+				buf.WriteByte(hex[r&0xf])
+
+				continue
+			}
+
+			buf.WriteRune(r)
+		}
+	}
+
+	buf.WriteByte('"')
+}
@@ -0,0 +1,71 @@
+package license
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "whitespace and key order",
+			input:    "{ \"b\": [1, 2, {\"y\": null, \"x\": true}],\n\t\"a\": \"text\" }",
+			expected: `{"a":"text","b":[1,2,{"x":true,"y":null}]}`,
+		},
+		{
+			name:     "utf-16 key order",
+			input:    `{"\u20ac":1,"\r":2,"\ufb33":3,"1":4,"\ud83d\ude00":5,"\u0080":6,"\u00f6":7}`,
+			expected: "{\"\\r\":2,\"1\":4,\"\u0080\":6,\"\u00f6\":7,\"\u20ac\":1,\"\U0001F600\":5,\"\ufb33\":3}",
+		},
+		{
+			name:     "numbers",
+			input:    `[333333333.33333329, 4.50, 2e-3, 0.000000000000000000000000001, -0, 1e-7, 1.5e-300, 100]`,
+			expected: `[333333333.3333333,4.5,0.002,1e-27,0,1e-7,1.5e-300,100]`,
+		},
+		{
+			name:     "integers",
+			input:    `[9007199254740993, -9223372036854775808, 12345678901234567890, 1000000000000000000000]`,
+			expected: `[9007199254740993,-9223372036854775808,12345678901234567890,1000000000000000000000]`,
+		},
+		{
+			name:     "integral values in any notation",
+			input:    `[1e21, 1E30, 100.0, 1.5e1, 9007199254740993.0, 90071992547409930e-1, -1e2]`,
+			expected: `[1000000000000000000000,1000000000000000000000000000000,100,15,9007199254740993,9007199254740993,-100]`,
+		},
+		{
+			name:     "string escaping",
+			input:    `"\u20ac\u0024\u000F\u000aA'\u0042\u0022\u005c\\\"\/<>&"`,
+			expected: "\"\u20ac$\\u000f\\nA'B\\\"\\\\\\\\\\\"/<>&\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := canonicalJSON(json.RawMessage(tt.input))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(got))
+		})
+	}
+
+	t.Run("re-marshaled through float64", func(t *testing.T) {
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal([]byte(`{"n":1000000000000000000000,"m":12}`), &decoded))
+
+		remarshaled, err := json.Marshal(decoded)
+		require.NoError(t, err)
+
+		original, err := canonicalJSON(json.RawMessage(`{"n":1000000000000000000000,"m":12}`))
+		require.NoError(t, err)
+
+		got, err := canonicalJSON(json.RawMessage(remarshaled))
+		require.NoError(t, err)
+		assert.Equal(t, string(original), string(got))
+	})
+}
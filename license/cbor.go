@@ -0,0 +1,350 @@
+package license
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Minimal CBOR (RFC 8949) support for COSE: definite-length items encoded with the
+// core deterministic encoding rules, that is shortest arguments and sorted map keys.
+
+const (
+	cborUnsigned = 0
+	cborNegative = 1
+	cborBytes    = 2
+	cborText     = 3
+	cborArray    = 4
+	cborMap      = 5
+	cborTagged   = 6
+	cborSimple   = 7
+
+	cborMaxDepth = 32
+)
+
+var errCBOR = errors.New("invalid CBOR")
+
+// cborTag is a tagged CBOR data item.
+type cborTag struct {
+	Number  uint64
+	Content any
+}
+
+// appendCBOR appends the deterministic encoding of the value. Maps are map[any]any with
+// int64 or string keys, and integers are int64.
+func appendCBOR(buf []byte, value any) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, cborSimple<<5|22), nil
+
+	case bool:
+		if v {
+			return append(buf, cborSimple<<5|21), nil
+		}
+
+		return append(buf, cborSimple<<5|20), nil
+
+	case int64:
+		if v < 0 {
+			return appendCBORHead(buf, cborNegative, uint64(-(v + 1))), nil
+		}
+
+		return appendCBORHead(buf, cborUnsigned, uint64(v)), nil
+
+	case float64:
+		buf = append(buf, cborSimple<<5|27)
+
+		return binary.BigEndian.AppendUint64(buf, math.Float64bits(v)), nil
+
+	case []byte:
+		buf = appendCBORHead(buf, cborBytes, uint64(len(v)))
+
+		return append(buf, v...), nil
+
+	case string:
+		buf = appendCBORHead(buf, cborText, uint64(len(v)))
+
+		return append(buf, v...), nil
+
+	case []any:
+		buf = appendCBORHead(buf, cborArray, uint64(len(v)))
+
+		for _, item := range v {
+			var err error
+			if buf, err = appendCBOR(buf, item); err != nil {
+				return nil, err
+			}
+		}
+
+		return buf, nil
+
+	case map[any]any:
+		type entry struct {
+			key, value []byte
+		}
+
+		entries := make([]entry, 0, len(v))
+
+		for key, item := range v {
+			encodedKey, err := appendCBOR(nil, key)
+			if err != nil {
+				return nil, err
+			}
+
+			encodedValue, err := appendCBOR(nil, item)
+			if err != nil {
+				return nil, err
+			}
+
+			entries = append(entries, entry{key: encodedKey, value: encodedValue})
+		}
+
+		slices.SortFunc(entries, func(a, b entry) int {
+			return bytes.Compare(a.key, b.key)
+		})
+
+		buf = appendCBORHead(buf, cborMap, uint64(len(entries)))
+
+		for _, e := range entries {
+			buf = append(buf, e.key...)
+			buf = append(buf, e.value...)
+		}
+
+		return buf, nil
+
+	case cborTag:
+		buf = appendCBORHead(buf, cborTagged, v.Number)
+
+		return appendCBOR(buf, v.Content)
+
+	default:
+		return nil, fmt.Errorf("%w: unsupported type %T", errCBOR, value)
+	}
+}
+
+// appendCBORHead appends the initial byte and the shortest argument of a data item.
+func appendCBORHead(buf []byte, major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return append(buf, major<<5|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(buf, major<<5|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, major<<5|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, major<<5|26), uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, major<<5|27), arg)
+	}
+}
+
+// decodeCBOR decodes a single data item that must span all of data.
+func decodeCBOR(data []byte) (any, error) {
+	dec := cborDecoder{data: data}
+
+	value, err := dec.decode(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if dec.pos != len(data) {
+		return nil, fmt.Errorf("%w: unexpected data after item", errCBOR)
+	}
+
+	return value, nil
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) decode(depth int) (any, error) {
+	if depth > cborMaxDepth {
+		return nil, fmt.Errorf("%w: nested too deep", errCBOR)
+	}
+
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case cborUnsigned:
+		if arg > math.MaxInt64 {
+			return nil, fmt.Errorf("%w: integer overflow", errCBOR)
+		}
+
+		return int64(arg), nil
+
+	case cborNegative:
+		if arg > math.MaxInt64 {
+			return nil, fmt.Errorf("%w: integer overflow", errCBOR)
+		}
+
+		return -1 - int64(arg), nil
+
+	case cborBytes, cborText:
+		content, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		if major == cborText {
+			return string(content), nil
+		}
+
+		return bytes.Clone(content), nil
+
+	case cborArray:
+		// Every item takes at least one byte, which bounds the allocation.
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, fmt.Errorf("%w: truncated", errCBOR)
+		}
+
+		items := make([]any, 0, arg)
+
+		for range arg {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+
+		return items, nil
+
+	case cborMap:
+		if arg > uint64(len(d.data)-d.pos)/2 {
+			return nil, fmt.Errorf("%w: truncated", errCBOR)
+		}
+
+		items := make(map[any]any, arg)
+
+		for range arg {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, fmt.Errorf("%w: unsupported map key %T", errCBOR, key)
+			}
+
+			if _, ok := items[key]; ok {
+				return nil, fmt.Errorf("%w: duplicate map key %v", errCBOR, key)
+			}
+
+			if items[key], err = d.decode(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+
+		return items, nil
+
+	case cborTagged:
+		content, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		return cborTag{Number: arg, Content: content}, nil
+
+	default:
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22:
+			return nil, nil
+		case 25:
+			return halfToFloat64(uint16(arg)), nil
+		case 26:
+			return float64(math.Float32frombits(uint32(arg))), nil
+		case 27:
+			return math.Float64frombits(arg), nil
+		default:
+			return nil, fmt.Errorf("%w: unsupported simple value %d", errCBOR, info)
+		}
+	}
+}
+
+// head reads the initial byte and the argument of a data item.
+func (d *cborDecoder) head() (byte, byte, uint64, error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, 0, fmt.Errorf("%w: truncated", errCBOR)
+	}
+
+	initial := d.data[d.pos]
+	d.pos++
+
+	major, info := initial>>5, initial&0x1f
+
+	var size uint64
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		size = 1 << (info - 24)
+	default:
+		return 0, 0, 0, fmt.Errorf("%w: indefinite or reserved length", errCBOR)
+	}
+
+	content, err := d.read(size)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var arg uint64
+	for _, b := range content {
+		arg = arg<<8 | uint64(b)
+	}
+
+	return major, info, arg, nil
+}
+
+func (d *cborDecoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, fmt.Errorf("%w: truncated", errCBOR)
+	}
+
+	content := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+
+	return content, nil
+}
+
+// halfToFloat64 converts an IEEE 754 half-precision float.
+func halfToFloat64(half uint16) float64 {
+	exponent := int(half>>10) & 0x1f
+	mantissa := float64(half & 0x3ff)
+
+	var value float64
+
+	switch exponent {
+	case 0:
+		value = math.Ldexp(mantissa, -24)
+	case 0x1f:
+		if mantissa == 0 {
+			value = math.Inf(1)
+		} else {
+			value = math.NaN()
+		}
+	default:
+		value = math.Ldexp(mantissa+1024, exponent-25)
+	}
+
+	if half&0x8000 != 0 {
+		return -value
+	}
+
+	return value
+}
@@ -0,0 +1,102 @@
+package license
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendCBOR(t *testing.T) {
+	// Examples of RFC 8949 Appendix A.
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{name: "0", value: int64(0), expected: "00"},
+		{name: "23", value: int64(23), expected: "17"},
+		{name: "24", value: int64(24), expected: "1818"},
+		{name: "100", value: int64(100), expected: "1864"},
+		{name: "1000", value: int64(1000), expected: "1903e8"},
+		{name: "1000000", value: int64(1000000), expected: "1a000f4240"},
+		{name: "1000000000000", value: int64(1000000000000), expected: "1b000000e8d4a51000"},
+		{name: "-1", value: int64(-1), expected: "20"},
+		{name: "-1000", value: int64(-1000), expected: "3903e7"},
+		{name: "1.1", value: 1.1, expected: "fb3ff199999999999a"},
+		{name: "false", value: false, expected: "f4"},
+		{name: "true", value: true, expected: "f5"},
+		{name: "null", value: nil, expected: "f6"},
+		{name: "bytes", value: []byte{1, 2, 3, 4}, expected: "4401020304"},
+		{name: "text", value: "IETF", expected: "6449455446"},
+		{name: "array", value: []any{int64(1), []any{int64(2), int64(3)}}, expected: "8201820203"},
+		{name: "map", value: map[any]any{int64(3): int64(4), int64(1): int64(2)}, expected: "a201020304"},
+		{name: "map keys sorted", value: map[any]any{"b": int64(1), int64(-1): int64(2), int64(10): int64(3)}, expected: "a30a032002616201"},
+		{name: "tag", value: cborTag{Number: 1, Content: int64(1363896240)}, expected: "c11a514b67b0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := appendCBOR(nil, tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, hex.EncodeToString(encoded))
+
+			decoded, err := decodeCBOR(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, decoded)
+		})
+	}
+
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := appendCBOR(nil, struct{}{})
+		assert.ErrorIs(t, err, errCBOR)
+	})
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	t.Run("floats", func(t *testing.T) {
+		for encoded, expected := range map[string]float64{
+			"f93c00":     1.0,
+			"f97bff":     65504.0,
+			"f90001":     5.960464477539063e-8,
+			"f9c400":     -4.0,
+			"fa47c35000": 100000.0,
+		} {
+			data, err := hex.DecodeString(encoded)
+			require.NoError(t, err)
+
+			decoded, err := decodeCBOR(data)
+			require.NoError(t, err)
+			assert.Equal(t, expected, decoded, encoded)
+		}
+	})
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: ""},
+		{name: "truncated argument", data: "19"},
+		{name: "truncated text", data: "6449"},
+		{name: "huge array", data: "9bffffffffffffffff"},
+		{name: "huge map", data: "bb7fffffffffffffff"},
+		{name: "indefinite array", data: "9f01ff"},
+		{name: "integer overflow", data: "1bffffffffffffffff"},
+		{name: "unsupported map key", data: "a1f401"},
+		{name: "duplicate map key", data: "a201010102"},
+		{name: "trailing data", data: "0101"},
+		{name: "too deep", data: strings.Repeat("81", cborMaxDepth+2) + "01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+
+			_, err = decodeCBOR(data)
+			assert.ErrorIs(t, err, errCBOR)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package license
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+const (
+	issuerCertificateBlockType = "LICENSE ISSUER CERTIFICATE"
+
+	// maxCertificateChain is the maximum number of issuer certificates between the root key and the license.
+	maxCertificateChain = 4
+)
+
+// IssuerCertificate delegates issuing licenses from a root key to an issuing key, within constraints.
+// It is distributed as a PEM block of type "LICENSE ISSUER CERTIFICATE" signed by the root key, or by
+// the issuing key of the previous certificate in a chain. Zero constraints are not enforced.
+//
+// The validity window applies to the IssuedAt claim of the license, so licenses issued while the
+// certificate was valid remain valid after it expires. Revoke the issuing key in the Keyring of the
+// verifier to reject every license it issued.
+type IssuerCertificate struct {
+	PublicKey   ed25519.PublicKey `json:"pub"`           // Issuing Key
+	NotBefore   int64             `json:"nbf,omitempty"` // Valid From
+	ExpiredAt   int64             `json:"exp,omitempty"` // Valid Until
+	Types       []string          `json:"typ,omitempty"` // Allowed License Types
+	MaxDuration int64             `json:"dur,omitempty"` // Max License Duration (seconds, including grace period)
+	Features    []string          `json:"ftr,omitempty"` // Allowed Features ("api.*" allows "api.auth")
+
+	issuer string
+}
+
+// Encode signs the issuer certificate with the signer of the root or parent issuer, such as an
+// ed25519.PrivateKey, and returns it PEM encoded.
+func (c *IssuerCertificate) Encode(signer crypto.Signer) ([]byte, error) {
+	if len(c.PublicKey) != ed25519.PublicKeySize {
+		return nil, ErrInvalidPublicKey
+	}
+
+	if c.ExpiredAt > 0 && c.ExpiredAt <= c.NotBefore {
+		return nil, ErrNotBeforeTime
+	}
+
+	if c.MaxDuration < 0 {
+		return nil, ErrTime
+	}
+
+	for _, feature := range c.Features {
+		if !validEntitlement(feature) {
+			return nil, ErrEntitlementName
+		}
+	}
+
+	if signer == nil {
+		return nil, ErrPrivateKeyNotDefined
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return sealEnvelope(issuerCertificateBlockType, nil, data, signer, EncodeOptions{})
+}
+
+// DecodeIssuerCertificate decodes the PEM encoded issuer certificate and verifies its signature
+// using the ed25519 public keys. At least one public key is required.
+func DecodeIssuerCertificate(data []byte, publicKeys ...ed25519.PublicKey) (*IssuerCertificate, error) {
+	keyring, err := newDecodeKeyring(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyring == nil || keyring.Len() == 0 {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	dec := decoder{keyring: keyring}
+
+	return dec.decodeIssuerCertificate(data)
+}
+
+// KeyID returns the ID of the issuing key.
+func (c *IssuerCertificate) KeyID() string {
+	return KeyID(c.PublicKey)
+}
+
+// Issuer returns the ID of the key that signed the certificate.
+// It is empty if the certificate was decoded without signature verification.
+func (c *IssuerCertificate) Issuer() string {
+	return c.issuer
+}
+
+func (d *decoder) decodeIssuerCertificate(data []byte) (*IssuerCertificate, error) {
+	env, err := d.openEnvelope(data, issuerCertificateBlockType)
+	if err != nil {
+		return nil, err
+	}
+
+	var c IssuerCertificate
+	if err := json.Unmarshal(env.payload, &c); err != nil {
+		return nil, malformedError(CodeClaimsInvalid, LayerClaims, err)
+	}
+
+	if len(c.PublicKey) != ed25519.PublicKeySize {
+		return nil, malformedError(CodeClaimsInvalid, LayerClaims, ErrInvalidPublicKey)
+	}
+
+	if len(env.signers) > 0 {
+		c.issuer = env.signers[0]
+	}
+
+	return &c, nil
+}
+
+// verifyChain verifies the issuer certificates of the content from a trusted root key to the key
+// that signed the message, then the signatures of the message. It returns the certificates and the
+// IDs of the keys that verified a signature, starting with the issuing key.
+func (d *decoder) verifyChain(alg Algorithm, content licenseContent, message, signature []byte) ([]*IssuerCertificate, []string, error) {
+	if len(content.Certificates) > maxCertificateChain {
+		return nil, nil, newError(CodeCertificateInvalid, LayerSignature, fmt.Errorf("%w: more than %d certificates", ErrCertificateChain, maxCertificateChain))
+	}
+
+	issuers := d.keyring
+	certificates := make([]*IssuerCertificate, 0, len(content.Certificates))
+
+	for i, data := range content.Certificates {
+		dec := decoder{keyring: issuers, opts: d.opts}
+
+		certificate, err := dec.decodeIssuerCertificate([]byte(data))
+		if err != nil {
+			return nil, nil, newError(CodeCertificateInvalid, LayerSignature, fmt.Errorf("%w: certificate %d: %w", ErrCertificateChain, i+1, err))
+		}
+
+		if key, ok := d.keyring.Get(certificate.KeyID()); ok && key.Status == KeyRevoked {
+			return nil, nil, newError(CodeKeyRevoked, LayerSignature, fmt.Errorf("%w: %w", ErrVerifySignature, ErrKeyRevoked))
+		}
+
+		issuers = NewKeyring()
+		if _, err := issuers.Add(certificate.PublicKey, KeyActive); err != nil {
+			return nil, nil, newError(CodeCertificateInvalid, LayerSignature, err)
+		}
+
+		certificates = append(certificates, certificate)
+	}
+
+	keyID, err := issuers.verify(alg, content.KeyID, message, signature)
+	if err != nil {
+		return nil, nil, signatureError(err)
+	}
+
+	signers, err := d.verifyCoSignatures(content, message, []string{keyID})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return certificates, signers, nil
+}
+
+// permits returns an error wrapping ErrCertificateConstraint if the license exceeds the certificate constraints.
+func (c *IssuerCertificate) permits(lic *License) error {
+	if c.NotBefore > 0 && lic.IssuedAt < c.NotBefore {
+		return fmt.Errorf("%w: issued before the certificate is valid", ErrCertificateConstraint)
+	}
+
+	if c.ExpiredAt > 0 && (lic.IssuedAt == 0 || lic.IssuedAt >= c.ExpiredAt) {
+		return fmt.Errorf("%w: issued after the certificate expired", ErrCertificateConstraint)
+	}
+
+	if len(c.Types) > 0 && !slices.Contains(c.Types, lic.Type) {
+		return fmt.Errorf("%w: license type %q", ErrCertificateConstraint, lic.Type)
+	}
+
+	if c.MaxDuration > 0 {
+		if lic.IssuedAt == 0 || lic.ExpiredAt == 0 {
+			return fmt.Errorf("%w: license without issue or expire time", ErrCertificateConstraint)
+		}
+
+		if lic.ExpiredAt+lic.GracePeriod-lic.IssuedAt > c.MaxDuration {
+			return fmt.Errorf("%w: license duration", ErrCertificateConstraint)
+		}
+	}
+
+	if len(c.Features) > 0 {
+		for _, feature := range lic.Features {
+			if !slices.ContainsFunc(c.Features, func(allowed string) bool { return featureCovers(allowed, feature) }) {
+				return fmt.Errorf("%w: feature %q", ErrCertificateConstraint, feature)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkCertificates checks that the issuer certificates delegate to the signing public key and that
+// the license satisfies their constraints. The signature of the first certificate is not verified,
+// as the root public key is only known to the verifier.
+func (lic *License) checkCertificates(chain [][]byte, keyID string) error {
+	if len(chain) == 0 {
+		return nil
+	}
+
+	if len(chain) > maxCertificateChain {
+		return fmt.Errorf("%w: more than %d certificates", ErrCertificateChain, maxCertificateChain)
+	}
+
+	var issuer ed25519.PublicKey
+
+	for i, data := range chain {
+		var dec decoder
+
+		if issuer != nil {
+			dec.keyring = NewKeyring()
+			if _, err := dec.keyring.Add(issuer, KeyActive); err != nil {
+				return err
+			}
+		}
+
+		certificate, err := dec.decodeIssuerCertificate(data)
+		if err != nil {
+			return fmt.Errorf("%w: certificate %d: %w", ErrCertificateChain, i+1, err)
+		}
+
+		if err := certificate.permits(lic); err != nil {
+			return err
+		}
+
+		issuer = certificate.PublicKey
+	}
+
+	if KeyID(issuer) != keyID {
+		return fmt.Errorf("%w: the last certificate is not for the signing key", ErrCertificateChain)
+	}
+
+	return nil
+}
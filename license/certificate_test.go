@@ -0,0 +1,353 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssuerCertificate_Encode(t *testing.T) {
+	_, rootPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	issuingPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		certificate *IssuerCertificate
+		privateKey  ed25519.PrivateKey
+		wantErr     error
+	}{
+		{
+			name:        "invalid public key",
+			certificate: &IssuerCertificate{PublicKey: ed25519.PublicKey("short")},
+			privateKey:  rootPrivateKey,
+			wantErr:     ErrInvalidPublicKey,
+		},
+		{
+			name:        "expire time before not before time",
+			certificate: &IssuerCertificate{PublicKey: issuingPublicKey, NotBefore: 2000, ExpiredAt: 1000},
+			privateKey:  rootPrivateKey,
+			wantErr:     ErrNotBeforeTime,
+		},
+		{
+			name:        "negative max duration",
+			certificate: &IssuerCertificate{PublicKey: issuingPublicKey, MaxDuration: -1},
+			privateKey:  rootPrivateKey,
+			wantErr:     ErrTime,
+		},
+		{
+			name:        "invalid feature",
+			certificate: &IssuerCertificate{PublicKey: issuingPublicKey, Features: []string{"api..auth"}},
+			privateKey:  rootPrivateKey,
+			wantErr:     ErrEntitlementName,
+		},
+		{
+			name:        "nil private key",
+			certificate: &IssuerCertificate{PublicKey: issuingPublicKey},
+			wantErr:     ErrPrivateKeyNotDefined,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.certificate.Encode(tt.privateKey)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDecodeIssuerCertificate(t *testing.T) {
+	rootPublicKey, rootPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	issuingPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	certificate := &IssuerCertificate{
+		PublicKey:   issuingPublicKey,
+		NotBefore:   1000,
+		ExpiredAt:   2000,
+		Types:       []string{"trial"},
+		MaxDuration: 3600,
+		Features:    []string{"reports.*"},
+	}
+
+	encoded, err := certificate.Encode(rootPrivateKey)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), "-----BEGIN LICENSE ISSUER CERTIFICATE-----")
+
+	t.Run("trusted root", func(t *testing.T) {
+		decoded, err := DecodeIssuerCertificate(encoded, rootPublicKey)
+		require.NoError(t, err)
+		assert.Equal(t, certificate.PublicKey, decoded.PublicKey)
+		assert.Equal(t, certificate.Types, decoded.Types)
+		assert.Equal(t, certificate.Features, decoded.Features)
+		assert.Equal(t, KeyID(issuingPublicKey), decoded.KeyID())
+		assert.Equal(t, KeyID(rootPublicKey), decoded.Issuer())
+	})
+
+	t.Run("untrusted root", func(t *testing.T) {
+		otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		_, err = DecodeIssuerCertificate(encoded, otherPublicKey)
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("no public keys", func(t *testing.T) {
+		_, err := DecodeIssuerCertificate(encoded)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("license block", func(t *testing.T) {
+		licenseKey, err := (&License{ID: "not-a-certificate"}).Encode(rootPrivateKey)
+		require.NoError(t, err)
+
+		_, err = DecodeIssuerCertificate(licenseKey, rootPublicKey)
+		assert.ErrorIs(t, err, ErrMalformedLicense)
+	})
+}
+
+func TestIssuerCertificateChain(t *testing.T) {
+	rootPublicKey, rootPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	issuingPublicKey, issuingPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	now := time.Now().Unix()
+
+	certificate, err := (&IssuerCertificate{
+		PublicKey:   issuingPublicKey,
+		NotBefore:   now - 3600,
+		ExpiredAt:   now + 3600,
+		Types:       []string{"trial", "standard"},
+		MaxDuration: 30 * 86400,
+		Features:    []string{"reports.*", "export"},
+	}).Encode(rootPrivateKey)
+	require.NoError(t, err)
+
+	validLicense := func() *License {
+		return &License{
+			ID:        "delegated-license",
+			Type:      "trial",
+			IssuedAt:  now,
+			ExpiredAt: now + 14*86400,
+			Features:  []string{"reports.daily", "export"},
+		}
+	}
+
+	t.Run("valid chain", func(t *testing.T) {
+		encoded, err := validLicense().EncodeWithOptions(issuingPrivateKey, EncodeOptions{
+			Certificates: [][]byte{certificate},
+		})
+		require.NoError(t, err)
+
+		decoded, err := Decode(encoded, rootPublicKey)
+		require.NoError(t, err)
+		assert.Equal(t, "delegated-license", decoded.ID)
+		assert.Equal(t, KeyID(issuingPublicKey), decoded.SignedBy())
+
+		certificates := decoded.Certificates()
+		require.Len(t, certificates, 1)
+		assert.Equal(t, KeyID(issuingPublicKey), certificates[0].KeyID())
+		assert.Equal(t, KeyID(rootPublicKey), certificates[0].Issuer())
+	})
+
+	t.Run("issuing key without certificate", func(t *testing.T) {
+		encoded, err := validLicense().Encode(issuingPrivateKey)
+		require.NoError(t, err)
+
+		_, err = Decode(encoded, rootPublicKey)
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("untrusted root", func(t *testing.T) {
+		encoded, err := validLicense().EncodeWithOptions(issuingPrivateKey, EncodeOptions{
+			Certificates: [][]byte{certificate},
+		})
+		require.NoError(t, err)
+
+		otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		_, err = Decode(encoded, otherPublicKey)
+		assert.ErrorIs(t, err, ErrCertificateChain)
+		assert.ErrorIs(t, err, ErrUnknownKey)
+
+		var licenseErr *Error
+		require.ErrorAs(t, err, &licenseErr)
+		assert.Equal(t, CodeCertificateInvalid, licenseErr.Code)
+		assert.Equal(t, LayerSignature, licenseErr.Layer)
+	})
+
+	t.Run("revoked issuing key", func(t *testing.T) {
+		encoded, err := validLicense().EncodeWithOptions(issuingPrivateKey, EncodeOptions{
+			Certificates: [][]byte{certificate},
+		})
+		require.NoError(t, err)
+
+		keyring := NewKeyring()
+		_, err = keyring.Add(rootPublicKey, KeyActive)
+		require.NoError(t, err)
+		_, err = keyring.Add(issuingPublicKey, KeyRevoked)
+		require.NoError(t, err)
+
+		verifier, err := NewKeyringVerifier(keyring)
+		require.NoError(t, err)
+
+		_, err = verifier.Verify(encoded)
+		assert.ErrorIs(t, err, ErrKeyRevoked)
+	})
+
+	t.Run("certificate for another key", func(t *testing.T) {
+		_, otherPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		_, err = validLicense().EncodeWithOptions(otherPrivateKey, EncodeOptions{
+			Certificates: [][]byte{certificate},
+		})
+		assert.ErrorIs(t, err, ErrCertificateChain)
+	})
+
+	t.Run("encode rejects constraint violations", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			modify func(lic *License)
+		}{
+			{name: "license type", modify: func(lic *License) { lic.Type = "enterprise" }},
+			{name: "empty license type", modify: func(lic *License) { lic.Type = "" }},
+			{name: "feature", modify: func(lic *License) { lic.Features = []string{"admin"} }},
+			{name: "wildcard feature", modify: func(lic *License) { lic.Features = []string{"*"} }},
+			{name: "duration", modify: func(lic *License) { lic.ExpiredAt = now + 60*86400 }},
+			{name: "duration with grace period", modify: func(lic *License) { lic.GracePeriod = 20 * 86400 }},
+			{name: "perpetual", modify: func(lic *License) { lic.ExpiredAt = 0 }},
+			{name: "issued before validity", modify: func(lic *License) { lic.IssuedAt = now - 7200 }},
+			{name: "issued after expiry", modify: func(lic *License) { lic.IssuedAt = now + 7200; lic.ExpiredAt = now + 86400 }},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				lic := validLicense()
+				tt.modify(lic)
+
+				_, err := lic.EncodeWithOptions(issuingPrivateKey, EncodeOptions{
+					Certificates: [][]byte{certificate},
+				})
+				assert.ErrorIs(t, err, ErrCertificateConstraint)
+			})
+		}
+	})
+
+	t.Run("decode rejects constraint violations", func(t *testing.T) {
+		lic := validLicense()
+		lic.Type = "enterprise"
+
+		data, err := json.Marshal(lic)
+		require.NoError(t, err)
+
+		encoded, err := sealEnvelope(licenseBlockType, map[string]string{"id": lic.ID}, data, issuingPrivateKey, EncodeOptions{
+			Certificates: [][]byte{certificate},
+		})
+		require.NoError(t, err)
+
+		_, err = Decode(encoded, rootPublicKey)
+		assert.ErrorIs(t, err, ErrCertificateConstraint)
+
+		var licenseErr *Error
+		require.ErrorAs(t, err, &licenseErr)
+		assert.Equal(t, CodeCertificateConstraint, licenseErr.Code)
+		assert.Equal(t, LayerClaims, licenseErr.Layer)
+	})
+
+	t.Run("intermediate constraints apply", func(t *testing.T) {
+		leafPublicKey, leafPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		leafCertificate, err := (&IssuerCertificate{
+			PublicKey: leafPublicKey,
+			Types:     []string{"trial", "enterprise"},
+		}).Encode(issuingPrivateKey)
+		require.NoError(t, err)
+
+		chain := [][]byte{certificate, leafCertificate}
+
+		encoded, err := validLicense().EncodeWithOptions(leafPrivateKey, EncodeOptions{Certificates: chain})
+		require.NoError(t, err)
+
+		decoded, err := Decode(encoded, rootPublicKey)
+		require.NoError(t, err)
+		assert.Equal(t, KeyID(leafPublicKey), decoded.SignedBy())
+		assert.Len(t, decoded.Certificates(), 2)
+
+		lic := validLicense()
+		lic.Type = "enterprise"
+
+		_, err = lic.EncodeWithOptions(leafPrivateKey, EncodeOptions{Certificates: chain})
+		assert.ErrorIs(t, err, ErrCertificateConstraint)
+	})
+
+	t.Run("chain out of order", func(t *testing.T) {
+		leafPublicKey, leafPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		leafCertificate, err := (&IssuerCertificate{PublicKey: leafPublicKey}).Encode(issuingPrivateKey)
+		require.NoError(t, err)
+
+		_, err = validLicense().EncodeWithOptions(leafPrivateKey, EncodeOptions{
+			Certificates: [][]byte{leafCertificate, certificate},
+		})
+		assert.ErrorIs(t, err, ErrCertificateChain)
+	})
+
+	t.Run("chain too long", func(t *testing.T) {
+		chain := make([][]byte, maxCertificateChain+1)
+		for i := range chain {
+			chain[i] = certificate
+		}
+
+		_, err := validLicense().EncodeWithOptions(issuingPrivateKey, EncodeOptions{Certificates: chain})
+		assert.ErrorIs(t, err, ErrCertificateChain)
+
+		data, err := json.Marshal(validLicense())
+		require.NoError(t, err)
+
+		encoded, err := sealEnvelope(licenseBlockType, nil, data, issuingPrivateKey, EncodeOptions{Certificates: chain})
+		require.NoError(t, err)
+
+		_, err = Decode(encoded, rootPublicKey)
+		assert.ErrorIs(t, err, ErrCertificateChain)
+	})
+
+	t.Run("nested certificates", func(t *testing.T) {
+		data, err := json.Marshal(&IssuerCertificate{PublicKey: issuingPublicKey})
+		require.NoError(t, err)
+
+		nested, err := sealEnvelope(issuerCertificateBlockType, nil, data, issuingPrivateKey, EncodeOptions{
+			Certificates: [][]byte{certificate},
+		})
+		require.NoError(t, err)
+
+		_, err = DecodeIssuerCertificate(nested, rootPublicKey)
+		assert.ErrorIs(t, err, ErrCertificateChain)
+	})
+
+	t.Run("unverified decode", func(t *testing.T) {
+		encoded, err := validLicense().EncodeWithOptions(issuingPrivateKey, EncodeOptions{
+			Certificates: [][]byte{certificate},
+		})
+		require.NoError(t, err)
+
+		decoded, err := DecodeUnverified(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, "delegated-license", decoded.ID)
+		assert.Empty(t, decoded.Certificates())
+	})
+}
@@ -0,0 +1,334 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Compact license keys are a signature and the license claims encoded as Crockford base32 in
+// dash-separated groups. Every group ends with a Luhn mod 32 check character computed over
+// the group number and the group characters, so a mistyped, swapped or missing character
+// or group is reported with the group it was found in.
+const (
+	compactVersion1 = 1 // Ed25519 signature, optionally deflate compressed claims
+
+	compactGroupSize = 5 // data characters per group, excluding the check character
+	maxCompactBytes  = 160
+
+	compactUncompressed = 0
+	compactDeflate      = 1
+
+	// compactHeaderSize is the version and compression bytes before the signature.
+	compactHeaderSize = 2
+
+	// compactSigningDomain is signed with the claims, as the block type of PEM license keys,
+	// so no other signed document verifies as a compact license key.
+	compactSigningDomain = "LICENSE COMPACT KEY"
+)
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// CompactGroupError is returned when a group of a compact license key has an invalid character
+// or check character. Group is the 1-based position of the group in the key.
+type CompactGroupError struct {
+	Group int
+	Err   error
+}
+
+func (e *CompactGroupError) Error() string {
+	return fmt.Sprintf("compact license key group %d: %v", e.Group, e.Err)
+}
+
+func (e *CompactGroupError) Unwrap() error {
+	return e.Err
+}
+
+// EncodeCompact signs the license with the ed25519 private key and returns it as a compact,
+// human-typeable license key. It returns ErrCompactTooLarge if the claims do not fit.
+func (lic *License) EncodeCompact(privateKey ed25519.PrivateKey) (string, error) {
+	if err := lic.validateClaims(); err != nil {
+		return "", err
+	}
+
+	if privateKey == nil {
+		return "", ErrPrivateKeyNotDefined
+	}
+
+	payload, err := json.Marshal(lic)
+	if err != nil {
+		return "", err
+	}
+
+	compression, claims := byte(compactUncompressed), payload
+
+	if compressed, err := compress(payload); err == nil && len(compressed) < len(payload) {
+		compression, claims = compactDeflate, compressed
+	}
+
+	data := make([]byte, 0, compactHeaderSize+ed25519.SignatureSize+len(claims))
+	data = append(data, compactVersion1, compression)
+	data = append(data, ed25519.Sign(privateKey, signingMessage(compactSigningDomain, payload))...)
+	data = append(data, claims...)
+
+	if len(data) > maxCompactBytes {
+		return "", fmt.Errorf("%w: %d bytes, the maximum is %d", ErrCompactTooLarge, len(data), maxCompactBytes)
+	}
+
+	return encodeCompactGroups(data), nil
+}
+
+// DecodeCompact decodes the compact license key and verifies the signature using the ed25519 public keys.
+// At least one public key is required.
+// Dashes, spaces and letter case are ignored, and the letters I, L and O are read as 1, 1 and 0.
+func DecodeCompact(key string, publicKeys ...ed25519.PublicKey) (*License, error) {
+	keyring, err := newDecodeKeyring(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyring == nil || keyring.Len() == 0 {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	dec := decoder{keyring: keyring}
+
+	return dec.decodeCompact(key)
+}
+
+// DecodeCompactUnverified decodes the compact license key without verifying the signature.
+// It is intended for inspection tools and must not be used to grant access.
+func DecodeCompactUnverified(key string) (*License, error) {
+	dec := decoder{}
+
+	return dec.decodeCompact(key)
+}
+
+// VerifyCompact decodes the compact license key and verifies the signature.
+func (v *Verifier) VerifyCompact(key string) (*License, error) {
+	if !v.ready() {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	lic, err := v.dec.decodeCompact(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.checkRevoked(lic)
+}
+
+func (d *decoder) decodeCompact(key string) (*License, error) {
+	// Every byte takes 1.6 data characters plus check characters and separators.
+	if len(key) > 4*maxCompactBytes {
+		return nil, newError(CodeTooLarge, LayerPEM, &LimitError{Limit: "input", Max: 4 * maxCompactBytes})
+	}
+
+	data, err := decodeCompactGroups(key)
+	if err != nil {
+		e := newError(CodeTypo, LayerPEM, err)
+
+		var groupErr *CompactGroupError
+		if errors.As(err, &groupErr) {
+			e.Message = fmt.Sprintf("The license key has a typo in group %d.", groupErr.Group)
+		}
+
+		return nil, e
+	}
+
+	if len(data) < compactHeaderSize+ed25519.SignatureSize {
+		return nil, newError(CodeMalformed, LayerPEM, ErrMalformedLicense)
+	}
+
+	if data[0] != compactVersion1 {
+		return nil, newError(CodeUnsupportedFormat, LayerPEM, fmt.Errorf("%w: compact %d", ErrUnsupportedVersion, data[0]))
+	}
+
+	signature := data[compactHeaderSize : compactHeaderSize+ed25519.SignatureSize]
+	payload := data[compactHeaderSize+ed25519.SignatureSize:]
+
+	switch data[1] {
+	case compactUncompressed:
+	case compactDeflate:
+		payload, err = decompressLimit(payload, d.opts.withDefaults().MaxDecompressedBytes)
+		if errors.As(err, new(*LimitError)) {
+			return nil, newError(CodeTooLarge, LayerCompression, err)
+		} else if err != nil {
+			return nil, malformedError(CodeCorrupted, LayerCompression, err)
+		}
+	default:
+		return nil, newError(CodeUnsupportedFormat, LayerPEM, fmt.Errorf("%w: compact %d", ErrUnsupportedCompression, data[1]))
+	}
+
+	message := signingMessage(compactSigningDomain, payload)
+
+	var signers []string
+
+	if d.keyring != nil {
+		keyID, err := d.keyring.verify(AlgorithmEd25519, "", message, signature)
+		if err != nil {
+			return nil, signatureError(err)
+		}
+
+		signers = []string{keyID}
+	}
+
+	license, err := d.decodeClaims(payload, signers)
+	if err != nil {
+		return nil, err
+	}
+
+	license.signed = message
+
+	return license, nil
+}
+
+// encodeCompactGroups returns the data as Crockford base32 groups with check characters.
+func encodeCompactGroups(data []byte) string {
+	var (
+		symbols []byte
+		buffer  uint
+		bits    uint
+	)
+
+	for _, b := range data {
+		buffer = buffer<<8 | uint(b)
+		bits += 8
+
+		for bits >= 5 {
+			bits -= 5
+			symbols = append(symbols, byte(buffer>>bits&0x1f))
+		}
+	}
+
+	if bits > 0 {
+		symbols = append(symbols, byte(buffer<<(5-bits)&0x1f))
+	}
+
+	var key strings.Builder
+
+	for group := 0; len(symbols) > 0; group++ {
+		n := min(compactGroupSize, len(symbols))
+
+		if group > 0 {
+			key.WriteByte('-')
+		}
+
+		for _, symbol := range symbols[:n] {
+			key.WriteByte(crockfordAlphabet[symbol])
+		}
+
+		key.WriteByte(crockfordAlphabet[compactCheckSymbol(group, symbols[:n])])
+
+		symbols = symbols[n:]
+	}
+
+	return key.String()
+}
+
+// decodeCompactGroups returns the data of Crockford base32 groups after verifying their check characters.
+func decodeCompactGroups(key string) ([]byte, error) {
+	groups := strings.FieldsFunc(key, func(r rune) bool {
+		return r == '-' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	})
+
+	if len(groups) == 0 {
+		return nil, ErrMalformedLicense
+	}
+
+	var (
+		data   []byte
+		buffer uint
+		bits   uint
+	)
+
+	for group, chars := range groups {
+		symbols := make([]byte, 0, len(chars))
+
+		for i := 0; i < len(chars); i++ {
+			symbol, ok := crockfordSymbol(chars[i])
+			if !ok {
+				return nil, &CompactGroupError{Group: group + 1, Err: fmt.Errorf("%w %q", ErrCompactCharacter, chars[i])}
+			}
+
+			symbols = append(symbols, symbol)
+		}
+
+		last := group == len(groups)-1
+
+		if len(symbols) < 2 || len(symbols) > compactGroupSize+1 || (!last && len(symbols) != compactGroupSize+1) {
+			return nil, &CompactGroupError{Group: group + 1, Err: ErrCompactGroupLength}
+		}
+
+		n := len(symbols) - 1
+
+		if compactCheckSymbol(group, symbols[:n]) != symbols[n] {
+			return nil, &CompactGroupError{Group: group + 1, Err: ErrCompactChecksum}
+		}
+
+		for _, symbol := range symbols[:n] {
+			buffer = buffer<<5 | uint(symbol)
+			bits += 5
+
+			if bits >= 8 {
+				bits -= 8
+				data = append(data, byte(buffer>>bits))
+			}
+		}
+	}
+
+	if bits >= 5 || buffer&(1<<bits-1) != 0 {
+		return nil, &CompactGroupError{Group: len(groups), Err: ErrCompactGroupLength}
+	}
+
+	return data, nil
+}
+
+// crockfordSymbol returns the value of a Crockford base32 character.
+func crockfordSymbol(c byte) (byte, bool) {
+	if c >= 'a' && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+
+	switch c {
+	case 'O':
+		c = '0'
+	case 'I', 'L':
+		c = '1'
+	}
+
+	i := strings.IndexByte(crockfordAlphabet, c)
+
+	return byte(i), i >= 0
+}
+
+// compactCheckSymbol returns the Luhn mod 32 check symbol of the group symbols, prefixed by
+// the group number so that swapped groups are detected.
+func compactCheckSymbol(group int, symbols []byte) byte {
+	const n = len(crockfordAlphabet)
+
+	factor, sum := 2, 0
+
+	for i := len(symbols); i >= 0; i-- {
+		var codePoint int
+		if i == 0 {
+			codePoint = group % n
+		} else {
+			codePoint = int(symbols[i-1])
+		}
+
+		addend := factor * codePoint
+		addend = addend/n + addend%n
+		sum += addend
+
+		if factor == 2 {
+			factor = 1
+		} else {
+			factor = 2
+		}
+	}
+
+	return byte((n - sum%n) % n)
+}
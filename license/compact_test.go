@@ -0,0 +1,217 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeCompact_DecodeCompact(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{
+		ID:        "appliance-42",
+		Type:      "pro",
+		IssuedAt:  1700000000,
+		ExpiredAt: 1800000000,
+		Features:  []string{"api.*"},
+	}
+
+	key, err := license.EncodeCompact(privateKey)
+	require.NoError(t, err)
+
+	groups := strings.Split(key, "-")
+	for _, group := range groups[:len(groups)-1] {
+		assert.Len(t, group, 6)
+	}
+
+	t.Run("roundtrip", func(t *testing.T) {
+		decoded, err := DecodeCompact(key, publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+		assert.Equal(t, license.ExpiredAt, decoded.ExpiredAt)
+		assert.True(t, decoded.HasFeature("api.auth"))
+		assert.Equal(t, KeyID(publicKey), decoded.SignedBy())
+	})
+
+	t.Run("typed loosely", func(t *testing.T) {
+		typed := strings.ToLower(strings.ReplaceAll(key, "-", " "))
+		typed = strings.NewReplacer("0", "o", "1", "l").Replace(typed)
+
+		decoded, err := DecodeCompact(typed, publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+	})
+
+	t.Run("unverified", func(t *testing.T) {
+		decoded, err := DecodeCompactUnverified(key)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+		assert.Empty(t, decoded.SignedBy())
+	})
+
+	t.Run("no public keys", func(t *testing.T) {
+		_, err := DecodeCompact(key)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+
+		_, err = DecodeCompact(key, nil)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		_, err = DecodeCompact(key, otherPublicKey)
+		assert.ErrorIs(t, err, ErrVerifySignature)
+	})
+
+	t.Run("verifier", func(t *testing.T) {
+		verifier, err := NewVerifier(publicKey)
+		require.NoError(t, err)
+
+		decoded, err := verifier.VerifyCompact(key)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+
+		revoked := verifier.WithRevocationList(&RevocationList{Entries: []RevocationEntry{{ID: license.ID}}})
+
+		_, err = revoked.VerifyCompact(key)
+		assert.ErrorIs(t, err, ErrLicenseRevoked)
+
+		_, err = (&Verifier{}).VerifyCompact(key)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("signature of the bare claims", func(t *testing.T) {
+		payload := []byte(`{"id":"appliance-42","ftr":["api.*","core.*"]}`)
+
+		data := append([]byte{compactVersion1, compactUncompressed}, ed25519.Sign(privateKey, payload)...)
+
+		_, err := DecodeCompact(encodeCompactGroups(append(data, payload...)), publicKey)
+		assert.ErrorIs(t, err, ErrVerifySignature)
+	})
+
+	t.Run("decompression limit", func(t *testing.T) {
+		repetitive := &License{ID: "appliance-42", Data: []byte(`{"text":"` + strings.Repeat("a", 200) + `"}`)}
+
+		compressed, err := repetitive.EncodeCompact(privateKey)
+		require.NoError(t, err)
+
+		verifier, err := NewVerifier(publicKey)
+		require.NoError(t, err)
+
+		_, err = verifier.WithDecodeOptions(DecodeOptions{MaxDecompressedBytes: 64}).VerifyCompact(compressed)
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+
+		var decodeErr *Error
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Equal(t, CodeTooLarge, decodeErr.Code)
+		assert.Equal(t, LayerCompression, decodeErr.Layer)
+	})
+
+	t.Run("invalid claims", func(t *testing.T) {
+		_, err := (&License{}).EncodeCompact(privateKey)
+		assert.ErrorIs(t, err, ErrLicenseIDNotDefined)
+
+		_, err = license.EncodeCompact(nil)
+		assert.ErrorIs(t, err, ErrPrivateKeyNotDefined)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		large := &License{ID: "appliance-42", Data: []byte(`{"text":"` + rand.Text() + rand.Text() + rand.Text() + rand.Text() + `"}`)}
+
+		_, err := large.EncodeCompact(privateKey)
+		assert.ErrorIs(t, err, ErrCompactTooLarge)
+	})
+}
+
+func TestDecodeCompact_Typos(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	key, err := (&License{ID: "appliance-42"}).EncodeCompact(privateKey)
+	require.NoError(t, err)
+
+	groups := strings.Split(key, "-")
+
+	// assertGroup asserts that decoding the groups fails in the given 1-based group.
+	assertGroup := func(t *testing.T, groups []string, group int) {
+		t.Helper()
+
+		_, err := DecodeCompact(strings.Join(groups, "-"), publicKey)
+
+		var groupErr *CompactGroupError
+		require.ErrorAs(t, err, &groupErr)
+		assert.Equal(t, group, groupErr.Group)
+
+		var licenseErr *Error
+		require.ErrorAs(t, err, &licenseErr)
+		assert.Equal(t, CodeTypo, licenseErr.Code)
+		assert.Contains(t, licenseErr.Message, "group")
+	}
+
+	t.Run("every substitution", func(t *testing.T) {
+		for i := range len(groups[3]) {
+			for _, c := range crockfordAlphabet {
+				if byte(c) == groups[3][i] {
+					continue
+				}
+
+				changed := append([]string(nil), groups...)
+				changed[3] = changed[3][:i] + string(c) + changed[3][i+1:]
+
+				assertGroup(t, changed, 4)
+			}
+		}
+	})
+
+	t.Run("invalid character", func(t *testing.T) {
+		changed := append([]string(nil), groups...)
+		changed[2] = "U" + changed[2][1:]
+
+		assertGroup(t, changed, 3)
+	})
+
+	t.Run("missing character", func(t *testing.T) {
+		changed := append([]string(nil), groups...)
+		changed[1] = changed[1][1:]
+
+		assertGroup(t, changed, 2)
+	})
+
+	t.Run("swapped groups", func(t *testing.T) {
+		changed := append([]string(nil), groups...)
+		changed[4], changed[5] = changed[5], changed[4]
+
+		_, err := DecodeCompact(strings.Join(changed, "-"), publicKey)
+		assert.ErrorIs(t, err, ErrCompactChecksum)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := DecodeCompact("  ", publicKey)
+		assert.ErrorIs(t, err, ErrMalformedLicense)
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		_, err := DecodeCompact(strings.Repeat(key, 4), publicKey)
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+	})
+}
+
+func TestCompactGroups(t *testing.T) {
+	for size := 1; size <= 16; size++ {
+		data := make([]byte, size)
+		_, err := rand.Read(data)
+		require.NoError(t, err)
+
+		decoded, err := decodeCompactGroups(encodeCompactGroups(data))
+		require.NoError(t, err, size)
+		assert.Equal(t, data, decoded)
+	}
+}
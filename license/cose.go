@@ -0,0 +1,552 @@
+package license
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// COSE_Sign1 (RFC 9052) encoding of a license. The payload is a CWT claims set (RFC 8392)
+// with integer keys; the license specific claims are in a single private claim:
+//
+//	 2  sub     Subscription (text)
+//	 4  exp     ExpiredAt (int)
+//	 5  nbf     NotBefore (int)
+//	 6  iat     IssuedAt (int)
+//	 7  cti     ID (bytes, UTF-8)
+//	-65537      license claims (map):
+//	     1  Customer (text)
+//	     2  Type (text)
+//	     3  GracePeriod (int)
+//	     4  Machine (map: 1 Components (array of text), 2 Threshold (int))
+//	     5  Features (array of text)
+//	     6  Limits (map of text to int)
+//	     7  Data (JSON converted to CBOR)
+const (
+	coseSign1Tag = 18
+
+	coseHeaderAlgorithm = 1
+	coseHeaderKeyID     = 4
+	coseAlgorithmEdDSA  = -8
+
+	cwtSubject   = 2
+	cwtExpiredAt = 4
+	cwtNotBefore = 5
+	cwtIssuedAt  = 6
+	cwtID        = 7
+	cwtLicense   = -65537
+
+	coseCustomer    = 1
+	coseType        = 2
+	coseGracePeriod = 3
+	coseMachine     = 4
+	coseFeatures    = 5
+	coseLimits      = 6
+	coseData        = 7
+
+	coseMachineComponents = 1
+	coseMachineThreshold  = 2
+)
+
+// EncodeCOSE signs the license with the ed25519 private key and returns it as a tagged COSE_Sign1
+// message with the EdDSA algorithm and a CWT claims set payload, for devices where JSON, base64
+// and flate are too heavy. The ID of the signing key is in the unprotected kid header.
+func (lic *License) EncodeCOSE(privateKey ed25519.PrivateKey) ([]byte, error) {
+	if err := lic.validateClaims(); err != nil {
+		return nil, err
+	}
+
+	if privateKey == nil {
+		return nil, ErrPrivateKeyNotDefined
+	}
+
+	claims, err := lic.cwtClaims()
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := appendCBOR(nil, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	protected, err := appendCBOR(nil, map[any]any{int64(coseHeaderAlgorithm): int64(coseAlgorithmEdDSA)})
+	if err != nil {
+		return nil, err
+	}
+
+	toBeSigned, err := coseSigStructure(protected, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID, err := base64.RawURLEncoding.DecodeString(KeyID(privateKey.Public().(ed25519.PublicKey)))
+	if err != nil {
+		return nil, err
+	}
+
+	return appendCBOR(nil, cborTag{
+		Number: coseSign1Tag,
+		Content: []any{
+			protected,
+			map[any]any{int64(coseHeaderKeyID): keyID},
+			payload,
+			ed25519.Sign(privateKey, toBeSigned),
+		},
+	})
+}
+
+// DecodeCOSE decodes a COSE_Sign1 license encoded with EncodeCOSE and verifies the signature using
+// the ed25519 public keys. At least one public key is required. Only the EdDSA algorithm is accepted.
+func DecodeCOSE(data []byte, publicKeys ...ed25519.PublicKey) (*License, error) {
+	keyring, err := newDecodeKeyring(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyring == nil || keyring.Len() == 0 {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	dec := decoder{keyring: keyring}
+
+	return dec.decodeCOSE(data)
+}
+
+// DecodeCOSEUnverified decodes a COSE_Sign1 license without verifying the signature.
+// It is intended for inspection tools and must not be used to grant access.
+func DecodeCOSEUnverified(data []byte) (*License, error) {
+	dec := decoder{}
+
+	return dec.decodeCOSE(data)
+}
+
+// VerifyCOSE decodes the COSE_Sign1 license and verifies the signature.
+func (v *Verifier) VerifyCOSE(data []byte) (*License, error) {
+	if !v.ready() {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	lic, err := v.dec.decodeCOSE(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.checkRevoked(lic)
+}
+
+func (d *decoder) decodeCOSE(data []byte) (*License, error) {
+	limits := d.opts.withDefaults()
+
+	if exceeds(int64(len(data)), limits.MaxInputBytes) {
+		return nil, newError(CodeTooLarge, LayerPEM, &LimitError{Limit: "input", Max: limits.MaxInputBytes})
+	}
+
+	message, err := decodeCBOR(data)
+	if err != nil {
+		return nil, malformedError(CodeMalformed, LayerEnvelope, err)
+	}
+
+	if tag, ok := message.(cborTag); ok {
+		if tag.Number != coseSign1Tag {
+			return nil, newError(CodeMalformed, LayerEnvelope, ErrMalformedLicense)
+		}
+
+		message = tag.Content
+	}
+
+	items, ok := message.([]any)
+	if !ok || len(items) != 4 {
+		return nil, newError(CodeMalformed, LayerEnvelope, ErrMalformedLicense)
+	}
+
+	protected, ok1 := items[0].([]byte)
+	unprotected, ok2 := items[1].(map[any]any)
+	payload, ok3 := items[2].([]byte)
+	signature, ok4 := items[3].([]byte)
+
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return nil, newError(CodeMalformed, LayerEnvelope, ErrMalformedLicense)
+	}
+
+	protectedHeaders, err := decodeCBOR(protected)
+	if err != nil {
+		return nil, malformedError(CodeCorrupted, LayerEnvelope, err)
+	}
+
+	headers, _ := protectedHeaders.(map[any]any)
+	if algorithm, _ := headers[int64(coseHeaderAlgorithm)].(int64); algorithm != coseAlgorithmEdDSA {
+		return nil, newError(CodeUnsupportedFormat, LayerEnvelope, fmt.Errorf("%w: %v", ErrUnsupportedAlgorithm, headers[int64(coseHeaderAlgorithm)]))
+	}
+
+	toBeSigned, err := coseSigStructure(protected, payload)
+	if err != nil {
+		return nil, malformedError(CodeCorrupted, LayerEnvelope, err)
+	}
+
+	var signers []string
+
+	if d.keyring != nil {
+		var kid string
+		if value, ok := unprotected[int64(coseHeaderKeyID)].([]byte); ok {
+			kid = base64.RawURLEncoding.EncodeToString(value)
+		}
+
+		keyID, err := d.keyring.verify(AlgorithmEd25519, kid, toBeSigned, signature)
+		if err != nil {
+			return nil, signatureError(err)
+		}
+
+		signers = []string{keyID}
+	}
+
+	claims, err := decodeCBOR(payload)
+	if err != nil {
+		return nil, malformedError(CodeClaimsInvalid, LayerClaims, err)
+	}
+
+	licenseClaims, err := licenseClaimsFromCWT(claims)
+	if err != nil {
+		return nil, malformedError(CodeClaimsInvalid, LayerClaims, err)
+	}
+
+	license, err := d.decodeClaims(licenseClaims, signers)
+	if err != nil {
+		return nil, err
+	}
+
+	license.signed = toBeSigned
+
+	return license, nil
+}
+
+// coseSigStructure returns the Sig_structure of a COSE_Sign1 message without external data.
+func coseSigStructure(protected, payload []byte) ([]byte, error) {
+	return appendCBOR(nil, []any{"Signature1", protected, []byte{}, payload})
+}
+
+// cwtClaims returns the CWT claims set of the license.
+func (lic *License) cwtClaims() (map[any]any, error) {
+	claims := map[any]any{}
+	private := map[any]any{}
+
+	setText := func(m map[any]any, key int64, value string) {
+		if value != "" {
+			m[key] = value
+		}
+	}
+
+	setInt := func(m map[any]any, key int64, value int64) {
+		if value != 0 {
+			m[key] = value
+		}
+	}
+
+	claims[int64(cwtID)] = []byte(lic.ID)
+	setText(claims, cwtSubject, lic.Subscription)
+	setInt(claims, cwtIssuedAt, lic.IssuedAt)
+	setInt(claims, cwtNotBefore, lic.NotBefore)
+	setInt(claims, cwtExpiredAt, lic.ExpiredAt)
+
+	setText(private, coseCustomer, lic.Customer)
+	setText(private, coseType, lic.Type)
+	setInt(private, coseGracePeriod, lic.GracePeriod)
+
+	if lic.Machine != nil {
+		machine := map[any]any{int64(coseMachineComponents): textArray(lic.Machine.Components)}
+		setInt(machine, coseMachineThreshold, int64(lic.Machine.Threshold))
+
+		private[int64(coseMachine)] = machine
+	}
+
+	if len(lic.Features) > 0 {
+		private[int64(coseFeatures)] = textArray(lic.Features)
+	}
+
+	if len(lic.Limits) > 0 {
+		limits := make(map[any]any, len(lic.Limits))
+		for name, value := range lic.Limits {
+			limits[name] = value
+		}
+
+		private[int64(coseLimits)] = limits
+	}
+
+	if len(lic.Data) > 0 {
+		data, err := jsonToCBORValue(lic.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		private[int64(coseData)] = data
+	}
+
+	if len(private) > 0 {
+		claims[int64(cwtLicense)] = private
+	}
+
+	return claims, nil
+}
+
+// licenseClaimsFromCWT returns the JSON license claims of a CWT claims set.
+func licenseClaimsFromCWT(value any) ([]byte, error) {
+	claims, ok := value.(map[any]any)
+	if !ok {
+		return nil, errors.New("claims set is not a map")
+	}
+
+	result := map[string]any{}
+
+	if id, ok := claims[int64(cwtID)]; ok {
+		idBytes, ok := id.([]byte)
+		if !ok {
+			return nil, errors.New("cti claim is not a byte string")
+		}
+
+		result["id"] = string(idBytes)
+	}
+
+	fields := []cwtField{
+		{claims, cwtSubject, "sub", isText},
+		{claims, cwtIssuedAt, "iat", isInt},
+		{claims, cwtNotBefore, "nbf", isInt},
+		{claims, cwtExpiredAt, "exp", isInt},
+	}
+
+	if value, ok := claims[int64(cwtLicense)]; ok {
+		private, ok := value.(map[any]any)
+		if !ok {
+			return nil, errors.New("license claim is not a map")
+		}
+
+		fields = append(fields, []cwtField{
+			{private, coseCustomer, "cus", isText},
+			{private, coseType, "typ", isText},
+			{private, coseGracePeriod, "grc", isInt},
+			{private, coseFeatures, "ftr", isTextArray},
+			{private, coseLimits, "lim", isLimits},
+		}...)
+
+		if value, ok := private[int64(coseMachine)]; ok {
+			machine, ok := value.(map[any]any)
+			if !ok || !isTextArray(machine[int64(coseMachineComponents)]) {
+				return nil, errors.New("machine claim is not a valid map")
+			}
+
+			binding := map[string]any{"cmp": machine[int64(coseMachineComponents)]}
+
+			if threshold, ok := machine[int64(coseMachineThreshold)]; ok {
+				if !isInt(threshold) {
+					return nil, errors.New("machine threshold is not an integer")
+				}
+
+				binding["thr"] = threshold
+			}
+
+			result["mch"] = binding
+		}
+
+		if value, ok := private[int64(coseData)]; ok {
+			data, err := cborToJSONValue(value)
+			if err != nil {
+				return nil, err
+			}
+
+			result["dat"] = data
+		}
+	}
+
+	for _, field := range fields {
+		value, ok := field.claims[field.key]
+		if !ok {
+			continue
+		}
+
+		if !field.check(value) {
+			return nil, fmt.Errorf("%s claim has an invalid type", field.name)
+		}
+
+		if limits, ok := value.(map[any]any); ok {
+			value = stringKeys(limits)
+		}
+
+		result[field.name] = value
+	}
+
+	return json.Marshal(result)
+}
+
+// cwtField maps a CWT claim to the JSON license claim of the given name.
+type cwtField struct {
+	claims map[any]any
+	key    int64
+	name   string
+	check  func(any) bool
+}
+
+func textArray(values []string) []any {
+	items := make([]any, len(values))
+	for i, value := range values {
+		items[i] = value
+	}
+
+	return items
+}
+
+func isText(value any) bool {
+	_, ok := value.(string)
+	return ok
+}
+
+func isInt(value any) bool {
+	_, ok := value.(int64)
+	return ok
+}
+
+func isTextArray(value any) bool {
+	items, ok := value.([]any)
+	if !ok {
+		return false
+	}
+
+	for _, item := range items {
+		if !isText(item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isLimits(value any) bool {
+	limits, ok := value.(map[any]any)
+	if !ok {
+		return false
+	}
+
+	for key, item := range limits {
+		if !isText(key) || !isInt(item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// stringKeys returns the map with string keys; the keys must be text.
+func stringKeys(m map[any]any) map[string]any {
+	result := make(map[string]any, len(m))
+	for key, value := range m {
+		result[key.(string)] = value
+	}
+
+	return result
+}
+
+// jsonToCBORValue converts JSON to a value for appendCBOR, with integers as int64 and other numbers as float64.
+// Integers outside the int64 range are rejected rather than rounded to float64.
+func jsonToCBORValue(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return convertJSONValue(value)
+}
+
+func convertJSONValue(value any) (any, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+
+		if !strings.ContainsAny(v.String(), ".eE") {
+			return nil, fmt.Errorf("%w: integer %s out of range", ErrInvalidData, v)
+		}
+
+		return v.Float64()
+
+	case []any:
+		items := make([]any, len(v))
+
+		for i, item := range v {
+			var err error
+			if items[i], err = convertJSONValue(item); err != nil {
+				return nil, err
+			}
+		}
+
+		return items, nil
+
+	case map[string]any:
+		items := make(map[any]any, len(v))
+
+		for key, item := range v {
+			var err error
+			if items[key], err = convertJSONValue(item); err != nil {
+				return nil, err
+			}
+		}
+
+		return items, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// cborToJSONValue converts a decoded CBOR value to a value for encoding/json.
+func cborToJSONValue(value any) (any, error) {
+	switch v := value.(type) {
+	case nil, bool, int64, string:
+		return v, nil
+
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, errors.New("data is not representable as JSON")
+		}
+
+		return v, nil
+
+	case []any:
+		items := make([]any, len(v))
+
+		for i, item := range v {
+			var err error
+			if items[i], err = cborToJSONValue(item); err != nil {
+				return nil, err
+			}
+		}
+
+		return items, nil
+
+	case map[any]any:
+		items := make(map[string]any, len(v))
+
+		for key, item := range v {
+			name, ok := key.(string)
+			if !ok {
+				return nil, errors.New("data is not representable as JSON")
+			}
+
+			var err error
+			if items[name], err = cborToJSONValue(item); err != nil {
+				return nil, err
+			}
+		}
+
+		return items, nil
+
+	default:
+		return nil, errors.New("data is not representable as JSON")
+	}
+}
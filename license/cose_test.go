@@ -0,0 +1,239 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeCOSE_DecodeCOSE(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{
+		ID:           "cose-license",
+		Customer:     "customer-123",
+		Subscription: "sub-456",
+		Type:         "device",
+		IssuedAt:     1700000000,
+		NotBefore:    1700000000,
+		ExpiredAt:    1800000000,
+		GracePeriod:  86400,
+		Machine:      &MachineBinding{Components: []string{"a", "b", "c"}, Threshold: 2},
+		Features:     []string{"api.*"},
+		Limits:       map[string]int64{"core.users.max": 10, "core.devices.max": -1},
+		Data:         json.RawMessage(`{"org":"ACME","seats":[1,2.5,null,true],"nested":{"x":"y"}}`),
+	}
+
+	encoded, err := license.EncodeCOSE(privateKey)
+	require.NoError(t, err)
+
+	t.Run("structure", func(t *testing.T) {
+		// Tag 18, array of 4, protected header {1: -8}.
+		assert.Equal(t, "d28443a10127", hex.EncodeToString(encoded[:6]))
+	})
+
+	t.Run("same semantics as decode", func(t *testing.T) {
+		pemEncoded, err := license.Encode(privateKey)
+		require.NoError(t, err)
+
+		expected, err := Decode(pemEncoded, publicKey)
+		require.NoError(t, err)
+
+		decoded, err := DecodeCOSE(encoded, publicKey)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected.ID, decoded.ID)
+		assert.Equal(t, expected.Customer, decoded.Customer)
+		assert.Equal(t, expected.Subscription, decoded.Subscription)
+		assert.Equal(t, expected.Type, decoded.Type)
+		assert.Equal(t, expected.IssuedAt, decoded.IssuedAt)
+		assert.Equal(t, expected.NotBefore, decoded.NotBefore)
+		assert.Equal(t, expected.ExpiredAt, decoded.ExpiredAt)
+		assert.Equal(t, expected.GracePeriod, decoded.GracePeriod)
+		assert.Equal(t, expected.Machine, decoded.Machine)
+		assert.Equal(t, expected.Features, decoded.Features)
+		assert.Equal(t, expected.Limits, decoded.Limits)
+		assert.JSONEq(t, string(expected.Data), string(decoded.Data))
+		assert.Equal(t, expected.SignedBy(), decoded.SignedBy())
+
+		expectedFingerprint, err := expected.GetFingerprint()
+		require.NoError(t, err)
+
+		fingerprint, err := decoded.GetFingerprint()
+		require.NoError(t, err)
+		assert.Equal(t, expectedFingerprint, fingerprint)
+
+		assert.Less(t, len(encoded), len(pemEncoded))
+	})
+
+	t.Run("minimal license", func(t *testing.T) {
+		minimal, err := (&License{ID: "x"}).EncodeCOSE(privateKey)
+		require.NoError(t, err)
+
+		decoded, err := DecodeCOSE(minimal, publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, &License{ID: "x", signers: []string{KeyID(publicKey)}, signed: decoded.signed}, decoded)
+	})
+
+	t.Run("untagged", func(t *testing.T) {
+		decoded, err := DecodeCOSE(encoded[1:], publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+	})
+
+	t.Run("unverified", func(t *testing.T) {
+		decoded, err := DecodeCOSEUnverified(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+		assert.Empty(t, decoded.SignedBy())
+	})
+
+	t.Run("no public keys", func(t *testing.T) {
+		_, err := DecodeCOSE(encoded)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+
+		_, err = DecodeCOSE(encoded, nil)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("verifier", func(t *testing.T) {
+		verifier, err := NewVerifier(publicKey)
+		require.NoError(t, err)
+
+		decoded, err := verifier.VerifyCOSE(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+
+		_, err = verifier.WithRevocationList(&RevocationList{Entries: []RevocationEntry{{ID: license.ID}}}).VerifyCOSE(encoded)
+		assert.ErrorIs(t, err, ErrLicenseRevoked)
+
+		_, err = (&Verifier{}).VerifyCOSE(encoded)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("invalid claims", func(t *testing.T) {
+		_, err := (&License{}).EncodeCOSE(privateKey)
+		assert.ErrorIs(t, err, ErrLicenseIDNotDefined)
+
+		_, err = license.EncodeCOSE(nil)
+		assert.ErrorIs(t, err, ErrPrivateKeyNotDefined)
+	})
+
+	t.Run("data integer out of range", func(t *testing.T) {
+		large := &License{ID: "cose-license", Data: json.RawMessage(`{"big":12345678901234567890}`)}
+
+		_, err := large.EncodeCOSE(privateKey)
+		assert.ErrorIs(t, err, ErrInvalidData)
+	})
+}
+
+func TestDecodeCOSE_Errors(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	encoded, err := (&License{ID: "cose-license"}).EncodeCOSE(privateKey)
+	require.NoError(t, err)
+
+	message, err := decodeCBOR(encoded)
+	require.NoError(t, err)
+
+	items := message.(cborTag).Content.([]any)
+
+	// rebuild returns the COSE_Sign1 message with the items changed and signed again.
+	rebuild := func(protected []byte, claims any) []byte {
+		payload, err := appendCBOR(nil, claims)
+		require.NoError(t, err)
+
+		toBeSigned, err := coseSigStructure(protected, payload)
+		require.NoError(t, err)
+
+		data, err := appendCBOR(nil, []any{protected, items[1], payload, ed25519.Sign(privateKey, toBeSigned)})
+		require.NoError(t, err)
+
+		return data
+	}
+
+	tampered := append([]byte(nil), encoded...)
+	tampered[len(tampered)-70] ^= 1
+
+	tests := []struct {
+		name        string
+		data        []byte
+		key         ed25519.PublicKey
+		expectedErr error
+	}{
+		{
+			name:        "not cbor",
+			data:        []byte("license"),
+			key:         publicKey,
+			expectedErr: ErrMalformedLicense,
+		},
+		{
+			name:        "other tag",
+			data:        append([]byte{0xd1}, encoded[1:]...),
+			key:         publicKey,
+			expectedErr: ErrMalformedLicense,
+		},
+		{
+			name:        "tampered payload",
+			data:        tampered,
+			key:         publicKey,
+			expectedErr: ErrVerifySignature,
+		},
+		{
+			name:        "other key",
+			data:        encoded,
+			key:         otherPublicKey,
+			expectedErr: ErrUnknownKey,
+		},
+		{
+			name:        "other algorithm",
+			data:        rebuild([]byte{0xa1, 0x01, 0x26}, map[any]any{int64(cwtID): []byte("cose-license")}),
+			key:         publicKey,
+			expectedErr: ErrUnsupportedAlgorithm,
+		},
+		{
+			name:        "text cti",
+			data:        rebuild(items[0].([]byte), map[any]any{int64(cwtID): "cose-license"}),
+			key:         publicKey,
+			expectedErr: ErrMalformedLicense,
+		},
+		{
+			name:        "wrong claim type",
+			data:        rebuild(items[0].([]byte), map[any]any{int64(cwtExpiredAt): "tomorrow"}),
+			key:         publicKey,
+			expectedErr: ErrMalformedLicense,
+		},
+		{
+			name:        "signed claims without license ID",
+			data:        rebuild(items[0].([]byte), map[any]any{int64(cwtSubject): "user@example.com"}),
+			key:         publicKey,
+			expectedErr: ErrLicenseIDNotDefined,
+		},
+		{
+			name:        "claims not a map",
+			data:        rebuild(items[0].([]byte), []any{}),
+			key:         publicKey,
+			expectedErr: ErrMalformedLicense,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeCOSE(tt.data, tt.key)
+			assert.ErrorIs(t, err, tt.expectedErr)
+
+			var licenseErr *Error
+			assert.ErrorAs(t, err, &licenseErr)
+		})
+	}
+}
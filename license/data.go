@@ -0,0 +1,92 @@
+package license
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// DataValidator is implemented by license payload types that check their own content.
+// NewWithData and DecodeData call Validate after marshaling and unmarshaling the payload.
+type DataValidator interface {
+	Validate() error
+}
+
+// DataOptions configures how DecodeDataWithOptions unmarshals the license data.
+type DataOptions struct {
+	// Strict rejects data with fields that are not defined by the payload type.
+	Strict bool
+}
+
+// NewWithData returns a copy of the license with the payload marshaled into Data.
+func NewWithData[T any](lic License, payload T) (*License, error) {
+	if err := validateData(&payload); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidData, err)
+	}
+
+	lic.Data = data
+
+	return &lic, nil
+}
+
+// DecodeData unmarshals the license data into a payload of type T.
+func DecodeData[T any](lic *License) (T, error) {
+	return DecodeDataWithOptions[T](lic, DataOptions{})
+}
+
+// DecodeDataWithOptions is like DecodeData but unmarshals with the given options.
+func DecodeDataWithOptions[T any](lic *License, opts DataOptions) (T, error) {
+	var payload T
+
+	if len(lic.Data) == 0 {
+		return payload, ErrDataNotDefined
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(lic.Data))
+	if opts.Strict {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(&payload); err != nil {
+		return payload, fmt.Errorf("%w: %w", ErrInvalidData, err)
+	}
+
+	if dec.More() {
+		return payload, fmt.Errorf("%w: unexpected data after payload", ErrInvalidData)
+	}
+
+	if err := validateData(&payload); err != nil {
+		return payload, err
+	}
+
+	return payload, nil
+}
+
+// validateData calls the validation hook of the payload, if it has one.
+// A nil pointer payload of a type with a hook is rejected instead of calling the hook on nil.
+func validateData[T any](payload *T) error {
+	validator, ok := any(payload).(DataValidator)
+	if !ok {
+		validator, ok = any(*payload).(DataValidator)
+	}
+
+	if !ok {
+		return nil
+	}
+
+	if value := reflect.ValueOf(validator); value.Kind() == reflect.Pointer && value.IsNil() {
+		return fmt.Errorf("%w: payload is nil", ErrInvalidData)
+	}
+
+	if err := validator.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidData, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPayload struct {
+	Org   string `json:"org"`
+	Seats int    `json:"seats"`
+}
+
+func (p *testPayload) Validate() error {
+	if p.Seats < 0 {
+		return errors.New("seats must not be negative")
+	}
+
+	return nil
+}
+
+type testPlainPayload struct {
+	Org string `json:"org"`
+}
+
+func TestNewWithData(t *testing.T) {
+	t.Run("marshals payload", func(t *testing.T) {
+		base := License{ID: "data-license", Customer: "customer"}
+
+		license, err := NewWithData(base, testPayload{Org: "ACME", Seats: 10})
+		require.NoError(t, err)
+
+		assert.Equal(t, "data-license", license.ID)
+		assert.Equal(t, "customer", license.Customer)
+		assert.JSONEq(t, `{"org":"ACME","seats":10}`, string(license.Data))
+		assert.Nil(t, base.Data)
+	})
+
+	t.Run("validation hook", func(t *testing.T) {
+		_, err := NewWithData(License{ID: "data-license"}, testPayload{Seats: -1})
+		assert.ErrorIs(t, err, ErrInvalidData)
+	})
+
+	t.Run("unmarshalable payload", func(t *testing.T) {
+		_, err := NewWithData(License{ID: "data-license"}, make(chan int))
+		assert.ErrorIs(t, err, ErrInvalidData)
+	})
+
+	t.Run("pointer payload", func(t *testing.T) {
+		license, err := NewWithData(License{ID: "data-license"}, &testPayload{Org: "ACME"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"org":"ACME","seats":0}`, string(license.Data))
+
+		_, err = NewWithData[*testPayload](License{ID: "data-license"}, nil)
+		assert.ErrorIs(t, err, ErrInvalidData)
+	})
+}
+
+func TestDecodeData(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    json.RawMessage
+		opts    DataOptions
+		want    testPayload
+		wantErr error
+	}{
+		{
+			name: "valid",
+			data: json.RawMessage(`{"org":"ACME","seats":10}`),
+			want: testPayload{Org: "ACME", Seats: 10},
+		},
+		{
+			name: "unknown fields allowed",
+			data: json.RawMessage(`{"org":"ACME","extra":true}`),
+			want: testPayload{Org: "ACME"},
+		},
+		{
+			name:    "unknown fields in strict mode",
+			data:    json.RawMessage(`{"org":"ACME","extra":true}`),
+			opts:    DataOptions{Strict: true},
+			wantErr: ErrInvalidData,
+		},
+		{
+			name:    "wrong type",
+			data:    json.RawMessage(`{"seats":"ten"}`),
+			wantErr: ErrInvalidData,
+		},
+		{
+			name:    "trailing data",
+			data:    json.RawMessage(`{"org":"ACME"} {}`),
+			wantErr: ErrInvalidData,
+		},
+		{
+			name:    "validation hook",
+			data:    json.RawMessage(`{"seats":-1}`),
+			wantErr: ErrInvalidData,
+		},
+		{
+			name:    "no data",
+			wantErr: ErrDataNotDefined,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := DecodeDataWithOptions[testPayload](&License{Data: tt.data}, tt.opts)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, payload)
+		})
+	}
+
+	t.Run("without validation hook", func(t *testing.T) {
+		payload, err := DecodeData[testPlainPayload](&License{Data: json.RawMessage(`{"org":"ACME"}`)})
+		require.NoError(t, err)
+		assert.Equal(t, "ACME", payload.Org)
+	})
+
+	t.Run("pointer payload", func(t *testing.T) {
+		payload, err := DecodeData[*testPayload](&License{Data: json.RawMessage(`{"org":"ACME"}`)})
+		require.NoError(t, err)
+		assert.Equal(t, "ACME", payload.Org)
+
+		_, err = DecodeData[*testPayload](&License{Data: json.RawMessage(`null`)})
+		assert.ErrorIs(t, err, ErrInvalidData)
+	})
+
+	t.Run("roundtrip", func(t *testing.T) {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		license, err := NewWithData(License{ID: "data-license"}, testPayload{Org: "ACME", Seats: 5})
+		require.NoError(t, err)
+
+		encoded, err := license.Encode(privateKey)
+		require.NoError(t, err)
+
+		decoded, err := Decode(encoded, publicKey)
+		require.NoError(t, err)
+
+		payload, err := DecodeData[testPayload](decoded)
+		require.NoError(t, err)
+		assert.Equal(t, testPayload{Org: "ACME", Seats: 5}, payload)
+	})
+}
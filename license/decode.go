@@ -1,86 +1,149 @@
 package license
 
 import (
-	"bytes"
+	"crypto/ecdh"
 	"crypto/ed25519"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
-	"encoding/pem"
-	"os"
 )
 
 // DecodeFile decodes the PEM encoded license file and verifies the content signature using the ed25519 public key.
 func DecodeFile(path string, publicKeys ...ed25519.PublicKey) (*License, error) {
-	data, err := os.ReadFile(path)
+	return DecodeFileWithOptions(path, DecodeOptions{}, publicKeys...)
+}
+
+// DecodeFileWithOptions is like DecodeFile but bounds decoding with the given options.
+func DecodeFileWithOptions(path string, opts DecodeOptions, publicKeys ...ed25519.PublicKey) (*License, error) {
+	data, err := readFileLimit(path, opts.withDefaults().MaxInputBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	return Decode(data, publicKeys...)
+	return DecodeWithOptions(data, opts, publicKeys...)
 }
 
 // Decode decodes the PEM encoded license key and verifies the content signature using the ed25519 public key.
+// If no public keys are passed the signature is not verified; use Verifier to make verification mandatory.
+// Decoding is bounded by DefaultDecodeOptions.
 func Decode(data []byte, publicKeys ...ed25519.PublicKey) (*License, error) {
-	block, _ := pem.Decode(data)
-	if block == nil || block.Type != "LICENSE KEY" {
-		return nil, ErrMalformedLicense
-	}
+	return DecodeWithOptions(data, DecodeOptions{}, publicKeys...)
+}
 
-	decompressed, err := decompress(block.Bytes)
+// DecodeWithOptions is like Decode but bounds decoding with the given options.
+func DecodeWithOptions(data []byte, opts DecodeOptions, publicKeys ...ed25519.PublicKey) (*License, error) {
+	keyring, err := newDecodeKeyring(publicKeys)
 	if err != nil {
 		return nil, err
 	}
 
-	var content licenseContent
-	if err := json.Unmarshal(decompressed, &content); err != nil {
-		return nil, err
-	}
+	dec := decoder{keyring: keyring, opts: opts}
 
-	signature, err := base64.RawURLEncoding.DecodeString(content.Sign)
+	return dec.decode(data)
+}
+
+// DecodeWithRecipient decodes a license key encrypted to recipients using the X25519 private key
+// and verifies the content signature using the ed25519 public keys. At least one public key is required.
+func DecodeWithRecipient(data []byte, recipientKey *ecdh.PrivateKey, publicKeys ...ed25519.PublicKey) (*License, error) {
+	keyring, err := newDecodeKeyring(publicKeys)
 	if err != nil {
 		return nil, err
 	}
 
-	msgHashSum, err := base64.RawURLEncoding.DecodeString(content.DataHash)
-	if err != nil {
-		return nil, err
+	if keyring == nil || keyring.Len() == 0 {
+		return nil, ErrPublicKeyNotDefined
 	}
 
-	encryptedData, err := base64.RawURLEncoding.DecodeString(content.Data)
-	if err != nil {
-		return nil, err
+	dec := decoder{keyring: keyring}
+	if recipientKey != nil {
+		dec.recipientKeys = []*ecdh.PrivateKey{recipientKey}
+	}
+
+	return dec.decode(data)
+}
+
+// DecodeUnverified decodes the PEM encoded license key without verifying the content signature.
+// It is intended for inspection tools and must not be used to grant access.
+func DecodeUnverified(data []byte) (*License, error) {
+	dec := decoder{}
+
+	return dec.decode(data)
+}
+
+// newDecodeKeyring returns the keyring of the keys passed to Decode.
+// A nil keyring disables signature verification.
+func newDecodeKeyring(publicKeys []ed25519.PublicKey) (*Keyring, error) {
+	if publicKeys == nil {
+		return nil, nil
 	}
 
-	decryptedData, err := decryptData(encryptedData, signature, msgHashSum)
+	keyring := NewKeyring()
+
+	for _, key := range publicKeys {
+		if key == nil {
+			continue
+		}
+
+		if _, err := keyring.Add(key, KeyActive); err != nil {
+			return nil, err
+		}
+	}
+
+	return keyring, nil
+}
+
+type decoder struct {
+	keyring       *Keyring
+	recipientKeys []*ecdh.PrivateKey
+	opts          DecodeOptions
+}
+
+func (d *decoder) decode(data []byte) (*License, error) {
+	env, err := d.openEnvelope(data, licenseBlockType)
 	if err != nil {
 		return nil, err
 	}
 
-	msgHash := sha256.New()
-	if _, err = msgHash.Write(decryptedData); err != nil {
+	license, err := d.decodeClaims(env.payload, env.signers)
+	if err != nil {
 		return nil, err
 	}
-	msgHashCheckSum := msgHash.Sum(nil)
 
-	if !bytes.Equal(msgHashCheckSum, msgHashSum) {
-		return nil, ErrWrongVerifyChecksum
+	if headerID, ok := env.block.Headers["id"]; ok && license.ID != headerID {
+		return nil, newError(CodeIDMismatch, LayerClaims, ErrWrongVerifyID)
 	}
 
-	if publicKeys != nil {
-		if verified := verifySignature(decryptedData, signature, publicKeys); !verified {
-			return nil, ErrVerifySignature
+	for _, certificate := range env.certificates {
+		if err := certificate.permits(license); err != nil {
+			return nil, newError(CodeCertificateConstraint, LayerClaims, err)
 		}
 	}
 
-	var license License
+	license.certificates = env.certificates
 
-	if err := json.Unmarshal(decryptedData, &license); err != nil {
+	return license, nil
+}
+
+// decodeClaims unmarshals the signed payload of a license verified by the keys with the given IDs.
+func (d *decoder) decodeClaims(payload []byte, signers []string) (*License, error) {
+	if err := d.checkThreshold(signers); err != nil {
 		return nil, err
 	}
 
-	if headerID, ok := block.Headers["id"]; ok && license.ID != headerID {
-		return nil, ErrWrongVerifyID
+	license := License{
+		signers: signers,
+		signed:  payload,
+	}
+
+	if err := json.Unmarshal(payload, &license); err != nil {
+		return nil, malformedError(CodeClaimsInvalid, LayerClaims, err)
+	}
+
+	// Every encoder refuses a license without an ID, so a signed document without one is not a license.
+	if d.keyring != nil && len(license.ID) == 0 {
+		return nil, newError(CodeClaimsInvalid, LayerClaims, ErrLicenseIDNotDefined)
+	}
+
+	if limit := d.opts.withDefaults().MaxDataBytes; exceeds(int64(len(license.Data)), limit) {
+		return nil, newError(CodeTooLarge, LayerClaims, &LimitError{Limit: "data", Max: limit})
 	}
 
 	return &license, nil
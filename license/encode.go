@@ -1,24 +1,58 @@
 package license
 
 import (
+	"crypto"
+	"crypto/ecdh"
 	"crypto/ed25519"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
-	"encoding/pem"
 )
 
+// EncodeOptions configures how a license is encoded.
+type EncodeOptions struct {
+	// Recipients are the X25519 public keys the license data is encrypted to.
+	// Only the holders of the matching private keys can decode the license.
+	// If empty, the data is only obfuscated and anyone holding the license can read it.
+	Recipients []*ecdh.PublicKey
+
+	// CoSigners are the ed25519 signers, such as ed25519.PrivateKey, that sign the license in
+	// addition to the primary key, for verifiers that require signatures from several keys.
+	CoSigners []crypto.Signer
+
+	// Certificates are the PEM encoded issuer certificates delegating to the signing key,
+	// starting with the one signed by the root key. The license must satisfy their constraints.
+	Certificates [][]byte
+}
+
+// Encode signs the license with the ed25519 private key and returns it PEM encoded.
 func (lic *License) Encode(privateKey ed25519.PrivateKey) ([]byte, error) {
-	if len(lic.ID) == 0 {
-		return nil, ErrLicenseIDNotDefined
+	return lic.EncodeWithOptions(privateKey, EncodeOptions{})
+}
+
+// EncodeWithOptions signs the license with the ed25519 private key and returns it PEM encoded.
+func (lic *License) EncodeWithOptions(privateKey ed25519.PrivateKey, opts EncodeOptions) ([]byte, error) {
+	return lic.EncodeWithSignerOptions(privateKey, opts)
+}
+
+// EncodeWithSigner signs the license with the signer and returns it PEM encoded.
+// The signer must have a public key of a registered Algorithm and is only asked to Sign,
+// so the private key can be held by an HSM or a KMS.
+func (lic *License) EncodeWithSigner(signer crypto.Signer) ([]byte, error) {
+	return lic.EncodeWithSignerOptions(signer, EncodeOptions{})
+}
+
+// EncodeWithSignerOptions signs the license with the signer and returns it PEM encoded.
+func (lic *License) EncodeWithSignerOptions(signer crypto.Signer, opts EncodeOptions) ([]byte, error) {
+	if err := lic.validateClaims(); err != nil {
+		return nil, err
 	}
 
-	if lic.ExpiredAt > 0 && lic.ExpiredAt <= lic.IssuedAt {
-		return nil, ErrTime
+	_, keyID, err := signerKey(signer)
+	if err != nil {
+		return nil, err
 	}
 
-	if privateKey == nil {
-		return nil, ErrPrivateKeyNotDefined
+	if err := lic.checkCertificates(opts.Certificates, keyID); err != nil {
+		return nil, err
 	}
 
 	data, err := json.Marshal(lic)
@@ -26,36 +60,34 @@ func (lic *License) Encode(privateKey ed25519.PrivateKey) ([]byte, error) {
 		return nil, err
 	}
 
-	msgHashSum := sha256.Sum256(data)
+	return sealEnvelope(licenseBlockType, map[string]string{"id": lic.ID}, data, signer, opts)
+}
 
-	signature := ed25519.Sign(privateKey, data)
+// validateClaims checks the claims before the license is signed.
+func (lic *License) validateClaims() error {
+	if len(lic.ID) == 0 {
+		return ErrLicenseIDNotDefined
+	}
 
-	encryptedData, err := encryptData(data, signature, msgHashSum[:])
-	if err != nil {
-		return nil, err
+	if lic.ExpiredAt > 0 && lic.ExpiredAt <= lic.IssuedAt {
+		return ErrTime
 	}
 
-	content := licenseContent{
-		Data:     base64.RawURLEncoding.EncodeToString(encryptedData),
-		Sign:     base64.RawURLEncoding.EncodeToString(signature),
-		DataHash: base64.RawURLEncoding.EncodeToString(msgHashSum[:]),
+	if lic.ExpiredAt > 0 && lic.ExpiredAt <= lic.NotBefore {
+		return ErrNotBeforeTime
 	}
 
-	dataContent, err := json.Marshal(content)
-	if err != nil {
-		return nil, err
+	if lic.GracePeriod < 0 {
+		return ErrGracePeriod
 	}
 
-	compressed, err := compress(dataContent)
-	if err != nil {
-		return nil, err
+	if lic.Machine != nil && !lic.Machine.valid() {
+		return ErrMachineBinding
+	}
+
+	if !lic.validEntitlements() {
+		return ErrEntitlementName
 	}
 
-	return pem.EncodeToMemory(&pem.Block{
-		Type:  "LICENSE KEY",
-		Bytes: compressed,
-		Headers: map[string]string{
-			"id": lic.ID,
-		},
-	}), nil
+	return nil
 }
@@ -1,9 +1,15 @@
 package license
 
 import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
+	"io"
 	"strings"
 	"testing"
 	"time"
@@ -117,6 +123,26 @@ func TestLicense_Encode_Errors(t *testing.T) {
 			privateKey:  nil,
 			expectedErr: ErrTime,
 		},
+		{
+			name: "expired before not before",
+			license: &License{
+				ID:        "test-id",
+				IssuedAt:  time.Now().Unix(),
+				NotBefore: time.Now().Add(2 * time.Hour).Unix(),
+				ExpiredAt: time.Now().Add(time.Hour).Unix(),
+			},
+			privateKey:  nil,
+			expectedErr: ErrNotBeforeTime,
+		},
+		{
+			name: "negative grace period",
+			license: &License{
+				ID:          "test-id",
+				GracePeriod: -1,
+			},
+			privateKey:  nil,
+			expectedErr: ErrGracePeriod,
+		},
 	}
 
 	for _, tt := range tests {
@@ -127,6 +153,121 @@ func TestLicense_Encode_Errors(t *testing.T) {
 	}
 }
 
+// memorySigner is a crypto.Signer that keeps the private key out of reach of the encoder,
+// like an HSM or KMS backed signer.
+type memorySigner struct {
+	privateKey ed25519.PrivateKey
+	calls      int
+	err        error
+	corrupt    bool
+}
+
+func (s *memorySigner) Public() crypto.PublicKey {
+	return s.privateKey.Public()
+}
+
+func (s *memorySigner) Sign(random io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.calls++
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	signature, err := s.privateKey.Sign(random, message, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.corrupt {
+		signature[0] ^= 0xff
+	}
+
+	return signature, nil
+}
+
+func TestLicense_EncodeWithSigner(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{
+		ID:        "signer-license",
+		IssuedAt:  time.Now().Unix(),
+		ExpiredAt: time.Now().Add(time.Hour).Unix(),
+		Data:      json.RawMessage(`{"seats":5}`),
+	}
+
+	t.Run("matches private key encoding", func(t *testing.T) {
+		signer := &memorySigner{privateKey: privateKey}
+
+		encoded, err := license.EncodeWithSigner(signer)
+		require.NoError(t, err)
+		assert.Equal(t, 1, signer.calls)
+
+		// Ed25519 signatures are deterministic, so both paths derive the same encryption key.
+		expected, err := license.Encode(privateKey)
+		require.NoError(t, err)
+		assert.Equal(t, expected, encoded)
+
+		decoded, err := Decode(encoded, publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+		assert.Equal(t, license.Data, decoded.Data)
+		assert.Equal(t, KeyID(publicKey), decoded.SignedBy())
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		recipientKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		coSignerPublicKey, coSignerPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		coSigner := &memorySigner{privateKey: coSignerPrivateKey}
+
+		encoded, err := license.EncodeWithSignerOptions(&memorySigner{privateKey: privateKey}, EncodeOptions{
+			Recipients: []*ecdh.PublicKey{recipientKey.PublicKey()},
+			CoSigners:  []crypto.Signer{coSigner},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, coSigner.calls)
+
+		verifier, err := NewVerifier(publicKey, coSignerPublicKey)
+		require.NoError(t, err)
+
+		decoded, err := verifier.WithRecipientKeys(recipientKey).WithThreshold(2).Verify(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, license.Data, decoded.Data)
+	})
+
+	t.Run("signer error", func(t *testing.T) {
+		errHSM := errors.New("hsm unavailable")
+
+		_, err := license.EncodeWithSigner(&memorySigner{privateKey: privateKey, err: errHSM})
+		assert.ErrorIs(t, err, errHSM)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		_, err := license.EncodeWithSigner(&memorySigner{privateKey: privateKey, corrupt: true})
+		assert.ErrorIs(t, err, ErrVerifySignature)
+	})
+
+	t.Run("unsupported key type", func(t *testing.T) {
+		ecdsaKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		_, err = license.EncodeWithSigner(ecdsaKey)
+		assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+	})
+
+	t.Run("nil signer", func(t *testing.T) {
+		_, err := license.EncodeWithSigner(nil)
+		assert.ErrorIs(t, err, ErrPrivateKeyNotDefined)
+
+		_, err = license.EncodeWithSigner(ed25519.PrivateKey(nil))
+		assert.ErrorIs(t, err, ErrPrivateKeyNotDefined)
+	})
+}
+
 func TestLicense_Encode_Decode_Roundtrip(t *testing.T) {
 	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
 	require.NoError(t, err)
@@ -0,0 +1,102 @@
+package license
+
+import "strings"
+
+// wildcard is the last part of a feature or limit name that covers every name below its prefix.
+const wildcard = "*"
+
+// HasFeature reports whether the license grants the feature.
+// Features are dot-separated names; a feature ending in ".*" grants every feature below it,
+// so "api.*" grants "api.auth.google", and "*" grants every feature.
+func (lic *License) HasFeature(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, feature := range lic.Features {
+		if featureCovers(feature, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// featureCovers reports whether the feature, which may end in a wildcard, grants the named feature.
+func featureCovers(feature, name string) bool {
+	if feature == name || feature == wildcard {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(feature, wildcard)
+
+	return ok && strings.HasSuffix(prefix, ".") && strings.HasPrefix(name, prefix)
+}
+
+// Limit returns the value of the named limit and whether the license defines it.
+// An exact name takes precedence over the most specific wildcard, so "core.*"
+// provides "core.users.max" unless "core.users.max" or "core.users.*" is set.
+func (lic *License) Limit(name string) (int64, bool) {
+	if name == "" {
+		return 0, false
+	}
+
+	if value, ok := lic.Limits[name]; ok {
+		return value, true
+	}
+
+	for prefix := name; ; {
+		i := strings.LastIndexByte(prefix, '.')
+		if i < 0 {
+			break
+		}
+
+		prefix = prefix[:i]
+
+		if value, ok := lic.Limits[prefix+"."+wildcard]; ok {
+			return value, true
+		}
+	}
+
+	value, ok := lic.Limits[wildcard]
+
+	return value, ok
+}
+
+// validEntitlement reports whether name is a dot-separated name, optionally ending in a wildcard.
+func validEntitlement(name string) bool {
+	if name == wildcard {
+		return true
+	}
+
+	parts := strings.Split(name, ".")
+
+	for i, part := range parts {
+		if part == "" {
+			return false
+		}
+
+		if strings.Contains(part, wildcard) && (part != wildcard || i != len(parts)-1) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validEntitlements reports whether every feature and limit name is valid.
+func (lic *License) validEntitlements() bool {
+	for _, feature := range lic.Features {
+		if !validEntitlement(feature) {
+			return false
+		}
+	}
+
+	for name := range lic.Limits {
+		if !validEntitlement(name) {
+			return false
+		}
+	}
+
+	return true
+}
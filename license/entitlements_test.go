@@ -0,0 +1,115 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLicense_HasFeature(t *testing.T) {
+	license := &License{
+		Features: []string{"api", "api.auth.ldap", "module.*"},
+	}
+
+	tests := []struct {
+		name     string
+		license  *License
+		feature  string
+		expected bool
+	}{
+		{name: "exact", license: license, feature: "api", expected: true},
+		{name: "exact nested", license: license, feature: "api.auth.ldap", expected: true},
+		{name: "parent does not grant children", license: license, feature: "api.auth.google", expected: false},
+		{name: "wildcard child", license: license, feature: "module.auth", expected: true},
+		{name: "wildcard grandchild", license: license, feature: "module.auth.ldap", expected: true},
+		{name: "wildcard does not grant parent", license: license, feature: "module", expected: false},
+		{name: "wildcard does not grant sibling prefix", license: license, feature: "modules.auth", expected: false},
+		{name: "unknown", license: license, feature: "storage", expected: false},
+		{name: "empty", license: license, feature: "", expected: false},
+		{name: "global wildcard", license: &License{Features: []string{"*"}}, feature: "api.auth.google", expected: true},
+		{name: "no features", license: &License{}, feature: "api", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.license.HasFeature(tt.feature))
+		})
+	}
+}
+
+func TestLicense_Limit(t *testing.T) {
+	license := &License{
+		Limits: map[string]int64{
+			"core.users.max": 123,
+			"core.users.*":   50,
+			"core.*":         10,
+			"module.*":       0,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		license  *License
+		limit    string
+		value    int64
+		expected bool
+	}{
+		{name: "exact", license: license, limit: "core.users.max", value: 123, expected: true},
+		{name: "most specific wildcard", license: license, limit: "core.users.min", value: 50, expected: true},
+		{name: "parent wildcard", license: license, limit: "core.groups.max", value: 10, expected: true},
+		{name: "zero value is present", license: license, limit: "module.auth.ldap.directory.max", value: 0, expected: true},
+		{name: "unknown", license: license, limit: "storage.max", value: 0, expected: false},
+		{name: "empty", license: license, limit: "", value: 0, expected: false},
+		{name: "global wildcard", license: &License{Limits: map[string]int64{"*": 5}}, limit: "storage.max", value: 5, expected: true},
+		{name: "no limits", license: &License{}, limit: "core.users.max", value: 0, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := tt.license.Limit(tt.limit)
+			assert.Equal(t, tt.expected, ok)
+			assert.Equal(t, tt.value, value)
+		})
+	}
+}
+
+func TestLicense_Entitlements(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("roundtrip", func(t *testing.T) {
+		license := &License{
+			ID:       "entitlements-license",
+			Features: []string{"api", "api.auth.*"},
+			Limits:   map[string]int64{"core.users.max": 123},
+		}
+
+		encoded, err := license.Encode(privateKey)
+		require.NoError(t, err)
+
+		decoded, err := Decode(encoded, publicKey)
+		require.NoError(t, err)
+
+		assert.True(t, decoded.HasFeature("api.auth.google"))
+
+		value, ok := decoded.Limit("core.users.max")
+		assert.True(t, ok)
+		assert.Equal(t, int64(123), value)
+	})
+
+	t.Run("invalid names", func(t *testing.T) {
+		for _, license := range []*License{
+			{ID: "invalid", Features: []string{""}},
+			{ID: "invalid", Features: []string{"api..auth"}},
+			{ID: "invalid", Features: []string{"api.*.auth"}},
+			{ID: "invalid", Features: []string{"api.auth*"}},
+			{ID: "invalid", Limits: map[string]int64{".users": 1}},
+		} {
+			_, err := license.Encode(privateKey)
+			assert.ErrorIs(t, err, ErrEntitlementName)
+		}
+	})
+}
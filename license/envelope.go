@@ -0,0 +1,455 @@
+package license
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+const licenseBlockType = "LICENSE KEY"
+
+// Envelope format versions. Blocks without a version header are read as version 1.
+const (
+	envelopeVersion1 = 1 // no format headers, Ed25519 signature, deflate compression
+	envelopeVersion2 = 2 // version, algorithm and compression PEM headers
+
+	envelopeVersion = envelopeVersion2
+)
+
+// Envelope format PEM headers and their values. They are reserved in every block.
+const (
+	headerVersion     = "version"
+	headerAlgorithm   = "algorithm"
+	headerCompression = "compression"
+
+	compressionDeflate = "deflate"
+)
+
+// envelopeFormat identifies how an envelope is encoded.
+type envelopeFormat struct {
+	version     int
+	algorithm   Algorithm
+	compression string
+}
+
+// parseEnvelopeFormat returns the format of the envelope from its PEM headers.
+func parseEnvelopeFormat(headers map[string]string) (envelopeFormat, error) {
+	value, ok := headers[headerVersion]
+	if !ok {
+		return envelopeFormat{
+			version:     envelopeVersion1,
+			algorithm:   AlgorithmEd25519,
+			compression: compressionDeflate,
+		}, nil
+	}
+
+	version, err := strconv.Atoi(value)
+	if err != nil || version != envelopeVersion2 {
+		return envelopeFormat{}, fmt.Errorf("%w: %q", ErrUnsupportedVersion, value)
+	}
+
+	alg, ok := LookupAlgorithm(headers[headerAlgorithm])
+	if !ok {
+		return envelopeFormat{}, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, headers[headerAlgorithm])
+	}
+
+	format := envelopeFormat{
+		version:     version,
+		algorithm:   alg,
+		compression: headers[headerCompression],
+	}
+
+	if format.compression != compressionDeflate {
+		return envelopeFormat{}, fmt.Errorf("%w: %q", ErrUnsupportedCompression, format.compression)
+	}
+
+	return format, nil
+}
+
+// headers returns the PEM headers of the block with the format headers set.
+func (f envelopeFormat) headers(headers map[string]string) map[string]string {
+	result := make(map[string]string, len(headers)+3)
+	for key, value := range headers {
+		result[key] = value
+	}
+
+	result[headerVersion] = strconv.Itoa(f.version)
+	result[headerAlgorithm] = f.algorithm.Name()
+	result[headerCompression] = f.compression
+
+	return result
+}
+
+// EncodeBlock signs the payload with the signer, such as an ed25519.PrivateKey, and returns it PEM
+// encoded with the given block type and headers, using the same compressed and signed envelope as
+// license keys. The headers are not signed; the version, algorithm and compression headers are reserved.
+func EncodeBlock(blockType string, headers map[string]string, payload []byte, signer crypto.Signer) ([]byte, error) {
+	return sealEnvelope(blockType, headers, payload, signer, EncodeOptions{})
+}
+
+// DecodeBlock decodes a PEM block of the given type encoded with EncodeBlock and verifies the payload
+// signature using the ed25519 public keys. At least one public key is required.
+// It returns the unsigned PEM headers and the payload.
+func DecodeBlock(data []byte, blockType string, publicKeys ...ed25519.PublicKey) (map[string]string, []byte, error) {
+	keyring, err := newDecodeKeyring(publicKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if keyring == nil || keyring.Len() == 0 {
+		return nil, nil, ErrPublicKeyNotDefined
+	}
+
+	dec := decoder{keyring: keyring}
+
+	env, err := dec.openEnvelope(data, blockType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return env.block.Headers, env.payload, nil
+}
+
+// sealEnvelope signs the payload with the signer, encrypts and compresses it and returns it
+// PEM encoded with the given block type and headers.
+func sealEnvelope(blockType string, headers map[string]string, payload []byte, signer crypto.Signer, opts EncodeOptions) ([]byte, error) {
+	message := signingMessage(blockType, payload)
+
+	alg, keyID, signature, err := sign(signer, message)
+	if err != nil {
+		return nil, err
+	}
+
+	msgHashSum := sha256.Sum256(payload)
+
+	content := licenseContent{
+		Sign:     base64.RawURLEncoding.EncodeToString(signature),
+		DataHash: base64.RawURLEncoding.EncodeToString(msgHashSum[:]),
+		KeyID:    keyID,
+	}
+
+	for _, certificate := range opts.Certificates {
+		content.Certificates = append(content.Certificates, string(certificate))
+	}
+
+	for _, coSigner := range opts.CoSigners {
+		coSignerAlg, coSignerKeyID, coSignature, err := sign(coSigner, message)
+		if err != nil {
+			return nil, err
+		}
+
+		coSignatureContent := signatureContent{
+			KeyID: coSignerKeyID,
+			Sign:  base64.RawURLEncoding.EncodeToString(coSignature),
+		}
+
+		if coSignerAlg.Name() != AlgorithmEd25519.Name() {
+			coSignatureContent.Algorithm = coSignerAlg.Name()
+		}
+
+		content.Signatures = append(content.Signatures, coSignatureContent)
+	}
+
+	if len(opts.Recipients) > 0 {
+		content, err = sealRecipientContent(content, payload, opts.Recipients)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		encryptedData, err := encryptData(payload, signature, msgHashSum[:])
+		if err != nil {
+			return nil, err
+		}
+
+		content.Data = base64.RawURLEncoding.EncodeToString(encryptedData)
+	}
+
+	dataContent, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	compressed, err := compress(dataContent)
+	if err != nil {
+		return nil, err
+	}
+
+	format := envelopeFormat{
+		version:     envelopeVersion,
+		algorithm:   alg,
+		compression: compressionDeflate,
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:    blockType,
+		Bytes:   compressed,
+		Headers: format.headers(headers),
+	}), nil
+}
+
+// signingMessage returns the message signed for the payload of a block: the block type, a zero byte
+// and the payload. Binding the block type keeps a signed document of one type, such as an issuer
+// certificate, from verifying as a document of another type. License keys sign the bare payload,
+// as every release did, so products already in the field keep verifying them; no other block type
+// was ever signed without its domain, so none of them verifies as a license key.
+func signingMessage(blockType string, payload []byte) []byte {
+	if blockType == licenseBlockType {
+		return payload
+	}
+
+	message := make([]byte, 0, len(blockType)+1+len(payload))
+	message = append(message, blockType...)
+	message = append(message, 0)
+
+	return append(message, payload...)
+}
+
+// envelope is a decoded PEM block with its decrypted payload.
+type envelope struct {
+	block   *pem.Block
+	payload []byte
+
+	// signers are the IDs of the keys that verified a signature, starting with the primary key.
+	signers []string
+	// certificates are the verified issuer certificates, starting with the one signed by the root key.
+	certificates []*IssuerCertificate
+}
+
+// sign signs the message with the signer and returns the algorithm and ID of its key and the signature.
+// The signature is verified, as the content encryption key is derived from it and a faulty signer
+// would produce a license that can not be decoded.
+func sign(signer crypto.Signer, message []byte) (Algorithm, string, []byte, error) {
+	alg, keyID, err := signerKey(signer)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	signature, err := alg.Sign(signer, message)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if !alg.Verify(signer.Public(), message, signature) {
+		return nil, "", nil, ErrVerifySignature
+	}
+
+	return alg, keyID, signature, nil
+}
+
+// signerKey returns the algorithm and the key ID of the public key of the signer.
+func signerKey(signer crypto.Signer) (Algorithm, string, error) {
+	if signer == nil {
+		return nil, "", ErrPrivateKeyNotDefined
+	}
+
+	if privateKey, ok := signer.(ed25519.PrivateKey); ok && privateKey == nil {
+		return nil, "", ErrPrivateKeyNotDefined
+	}
+
+	alg, err := algorithmOf(signer.Public())
+	if err != nil {
+		return nil, "", err
+	}
+
+	keyID, err := algorithmKeyID(alg, signer.Public())
+	if err != nil {
+		return nil, "", err
+	}
+
+	return alg, keyID, nil
+}
+
+// openEnvelope decodes the PEM block of the given type, decrypts the payload and verifies its checksum
+// and, if the decoder has a keyring, its signatures and issuer certificates.
+func (d *decoder) openEnvelope(data []byte, blockType string) (*envelope, error) {
+	limits := d.opts.withDefaults()
+
+	if exceeds(int64(len(data)), limits.MaxInputBytes) {
+		return nil, newError(CodeTooLarge, LayerPEM, &LimitError{Limit: "input", Max: limits.MaxInputBytes})
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, newError(CodeMalformed, LayerPEM, ErrMalformedLicense)
+	}
+
+	if exceeds(int64(len(block.Headers)), int64(limits.MaxHeaders)) {
+		return nil, newError(CodeTooLarge, LayerPEM, &LimitError{Limit: "headers", Max: int64(limits.MaxHeaders)})
+	}
+
+	// Every supported version uses deflate compression, so the format only selects the
+	// signature algorithm and rejects future versions.
+	format, err := parseEnvelopeFormat(block.Headers)
+	if err != nil {
+		return nil, newError(CodeUnsupportedFormat, LayerPEM, err)
+	}
+
+	// Version 1 predates the signing domain and only existed for license keys.
+	if format.version == envelopeVersion1 && blockType != licenseBlockType {
+		return nil, newError(CodeUnsupportedFormat, LayerPEM, fmt.Errorf("%w: version 1 %s", ErrUnsupportedVersion, blockType))
+	}
+
+	decompressed, err := decompressLimit(block.Bytes, limits.MaxDecompressedBytes)
+	if errors.As(err, new(*LimitError)) {
+		return nil, newError(CodeTooLarge, LayerCompression, err)
+	} else if err != nil {
+		return nil, malformedError(CodeCorrupted, LayerCompression, err)
+	}
+
+	var content licenseContent
+	if err := json.Unmarshal(decompressed, &content); err != nil {
+		return nil, malformedError(CodeCorrupted, LayerEnvelope, err)
+	}
+
+	sealed := len(content.Recipients) > 0
+
+	if sealed {
+		if content, err = d.openRecipientContent(content); err != nil {
+			return nil, err
+		}
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(content.Sign)
+	if err != nil {
+		return nil, malformedError(CodeCorrupted, LayerEnvelope, err)
+	}
+
+	msgHashSum, err := base64.RawURLEncoding.DecodeString(content.DataHash)
+	if err != nil {
+		return nil, malformedError(CodeCorrupted, LayerEnvelope, err)
+	}
+
+	decryptedData, err := base64.RawURLEncoding.DecodeString(content.Data)
+	if err != nil {
+		return nil, malformedError(CodeCorrupted, LayerEnvelope, err)
+	}
+
+	if !sealed {
+		if decryptedData, err = decryptData(decryptedData, signature, msgHashSum); err != nil {
+			return nil, newError(CodeDecryptionFailed, LayerDecryption, err)
+		}
+	}
+
+	msgHashCheckSum := sha256.Sum256(decryptedData)
+
+	if !bytes.Equal(msgHashCheckSum[:], msgHashSum) {
+		return nil, newError(CodeChecksumMismatch, LayerChecksum, ErrWrongVerifyChecksum)
+	}
+
+	env := &envelope{
+		block:   block,
+		payload: decryptedData,
+	}
+
+	message := signingMessage(blockType, decryptedData)
+
+	if len(content.Certificates) > 0 && blockType == issuerCertificateBlockType {
+		return nil, newError(CodeMalformed, LayerEnvelope, fmt.Errorf("%w: nested issuer certificates", ErrCertificateChain))
+	}
+
+	switch {
+	case d.keyring == nil:
+	case len(content.Certificates) > 0:
+		env.certificates, env.signers, err = d.verifyChain(format.algorithm, content, message, signature)
+	default:
+		env.signers, err = d.verifySignatures(format.algorithm, content, message, signature)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// verifySignatures verifies the primary signature and the co-signatures of the message and returns
+// the IDs of the keys that verified one. Signatures of keys unknown to the keyring or revoked are
+// skipped, an invalid signature of a known key fails. If no signature verifies, the error of the
+// primary signature is returned.
+func (d *decoder) verifySignatures(alg Algorithm, content licenseContent, message, signature []byte) ([]string, error) {
+	var signers []string
+
+	primaryID, primaryErr := d.keyring.verify(alg, content.KeyID, message, signature)
+	if primaryErr == nil {
+		signers = append(signers, primaryID)
+	} else if !errors.Is(primaryErr, ErrUnknownKey) && !errors.Is(primaryErr, ErrKeyRevoked) {
+		return nil, signatureError(primaryErr)
+	}
+
+	signers, err := d.verifyCoSignatures(content, message, signers)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(signers) == 0 {
+		return nil, signatureError(primaryErr)
+	}
+
+	return signers, nil
+}
+
+// verifyCoSignatures verifies the co-signatures of the message against the keyring and appends the
+// IDs of the keys that verified one to signers, skipping unknown and revoked keys.
+func (d *decoder) verifyCoSignatures(content licenseContent, message []byte, signers []string) ([]string, error) {
+	for _, coSignature := range content.Signatures {
+		sig, err := base64.RawURLEncoding.DecodeString(coSignature.Sign)
+		if err != nil {
+			return nil, malformedError(CodeCorrupted, LayerEnvelope, err)
+		}
+
+		if coSignature.KeyID == "" {
+			return nil, newError(CodeMalformed, LayerEnvelope, ErrMalformedLicense)
+		}
+
+		alg := AlgorithmEd25519
+		if coSignature.Algorithm != "" {
+			var ok bool
+			if alg, ok = LookupAlgorithm(coSignature.Algorithm); !ok {
+				continue
+			}
+		}
+
+		keyID, err := d.keyring.verify(alg, coSignature.KeyID, message, sig)
+		if errors.Is(err, ErrUnknownKey) || errors.Is(err, ErrKeyRevoked) {
+			continue
+		} else if err != nil {
+			return nil, signatureError(err)
+		}
+
+		if !slices.Contains(signers, keyID) {
+			signers = append(signers, keyID)
+		}
+	}
+
+	return signers, nil
+}
+
+// checkThreshold returns an error if fewer keys than the decoder threshold verified a signature.
+// Without a keyring nothing is verified and there is no threshold.
+func (d *decoder) checkThreshold(signers []string) error {
+	if required := d.opts.threshold(); d.keyring != nil && len(signers) < required {
+		return newError(CodeSignatureThreshold, LayerSignature, fmt.Errorf("%w: %d of %d", ErrSignatureThreshold, len(signers), required))
+	}
+
+	return nil
+}
+
+// signatureError returns the error for a failed signature verification.
+func signatureError(err error) *Error {
+	switch {
+	case errors.Is(err, ErrUnknownKey):
+		return newError(CodeUnknownKey, LayerSignature, err)
+	case errors.Is(err, ErrKeyRevoked):
+		return newError(CodeKeyRevoked, LayerSignature, err)
+	default:
+		return newError(CodeSignatureInvalid, LayerSignature, err)
+	}
+}
@@ -0,0 +1,437 @@
+package license
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeBlock_DecodeBlock(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	payload := []byte(`{"custom":"payload"}`)
+
+	encoded, err := EncodeBlock("CUSTOM BLOCK", map[string]string{"name": "value"}, payload, privateKey)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), "-----BEGIN CUSTOM BLOCK-----")
+
+	t.Run("decode block", func(t *testing.T) {
+		headers, decoded, err := DecodeBlock(encoded, "CUSTOM BLOCK", publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, payload, decoded)
+		assert.Equal(t, "value", headers["name"])
+	})
+
+	t.Run("verifier decode block", func(t *testing.T) {
+		verifier, err := NewVerifier(publicKey)
+		require.NoError(t, err)
+
+		_, decoded, err := verifier.VerifyBlock(encoded, "CUSTOM BLOCK")
+		require.NoError(t, err)
+		assert.Equal(t, payload, decoded)
+
+		_, _, err = (&Verifier{}).VerifyBlock(encoded, "CUSTOM BLOCK")
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("wrong block type", func(t *testing.T) {
+		_, _, err := DecodeBlock(encoded, "OTHER BLOCK", publicKey)
+		assert.ErrorIs(t, err, ErrMalformedLicense)
+
+		_, err = Decode(encoded, publicKey)
+		assert.ErrorIs(t, err, ErrMalformedLicense)
+	})
+
+	t.Run("public key required", func(t *testing.T) {
+		_, _, err := DecodeBlock(encoded, "CUSTOM BLOCK")
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("untrusted key", func(t *testing.T) {
+		otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		_, _, err = DecodeBlock(encoded, "CUSTOM BLOCK", otherPublicKey)
+		assert.ErrorIs(t, err, ErrVerifySignature)
+	})
+}
+
+func TestEnvelopeVersion(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{ID: "versioned-license"}
+
+	encoded, err := license.Encode(privateKey)
+	require.NoError(t, err)
+
+	block, _ := pem.Decode(encoded)
+	require.NotNil(t, block)
+	assert.Equal(t, map[string]string{
+		"id":          "versioned-license",
+		"version":     "2",
+		"algorithm":   "ed25519",
+		"compression": "deflate",
+	}, block.Headers)
+
+	// withHeaders returns the license key with the PEM headers changed.
+	withHeaders := func(change func(headers map[string]string)) []byte {
+		headers := make(map[string]string, len(block.Headers))
+		for key, value := range block.Headers {
+			headers[key] = value
+		}
+
+		change(headers)
+
+		return pem.EncodeToMemory(&pem.Block{Type: block.Type, Headers: headers, Bytes: block.Bytes})
+	}
+
+	t.Run("unversioned block is version 1", func(t *testing.T) {
+		decoded, err := Decode(encodeVersion1(t, licenseBlockType, []byte(`{"id":"legacy-license"}`), privateKey), publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, "legacy-license", decoded.ID)
+	})
+
+	t.Run("removed format headers", func(t *testing.T) {
+		unversioned := withHeaders(func(headers map[string]string) {
+			delete(headers, "version")
+			delete(headers, "algorithm")
+			delete(headers, "compression")
+		})
+
+		decoded, err := Decode(unversioned, publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+	})
+
+	t.Run("version 1 is only read for license keys", func(t *testing.T) {
+		_, _, err := DecodeBlock(encodeVersion1(t, "CUSTOM BLOCK", []byte(`{"custom":"payload"}`), privateKey), "CUSTOM BLOCK", publicKey)
+		assert.ErrorIs(t, err, ErrUnsupportedVersion)
+	})
+
+	tests := []struct {
+		name        string
+		change      func(headers map[string]string)
+		expectedErr error
+	}{
+		{
+			name:        "future version",
+			change:      func(headers map[string]string) { headers["version"] = "3" },
+			expectedErr: ErrUnsupportedVersion,
+		},
+		{
+			name:        "invalid version",
+			change:      func(headers map[string]string) { headers["version"] = "v2" },
+			expectedErr: ErrUnsupportedVersion,
+		},
+		{
+			name:        "unknown algorithm",
+			change:      func(headers map[string]string) { headers["algorithm"] = "rsa" },
+			expectedErr: ErrUnsupportedAlgorithm,
+		},
+		{
+			name:        "missing algorithm",
+			change:      func(headers map[string]string) { delete(headers, "algorithm") },
+			expectedErr: ErrUnsupportedAlgorithm,
+		},
+		{
+			name:        "unknown compression",
+			change:      func(headers map[string]string) { headers["compression"] = "zstd" },
+			expectedErr: ErrUnsupportedCompression,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(withHeaders(tt.change), publicKey)
+			assert.ErrorIs(t, err, tt.expectedErr)
+
+			var licenseErr *Error
+			require.ErrorAs(t, err, &licenseErr)
+			assert.Equal(t, CodeUnsupportedFormat, licenseErr.Code)
+		})
+	}
+}
+
+// encodeVersion1 returns the payload in a version 1 envelope, without format headers and with
+// a signature of the bare payload.
+func encodeVersion1(t *testing.T, blockType string, payload []byte, privateKey ed25519.PrivateKey) []byte {
+	t.Helper()
+
+	signature := ed25519.Sign(privateKey, payload)
+	hash := sha256.Sum256(payload)
+
+	encrypted, err := encryptData(payload, signature, hash[:])
+	require.NoError(t, err)
+
+	content, err := json.Marshal(licenseContent{
+		Sign:     base64.RawURLEncoding.EncodeToString(signature),
+		DataHash: base64.RawURLEncoding.EncodeToString(hash[:]),
+		Data:     base64.RawURLEncoding.EncodeToString(encrypted),
+	})
+	require.NoError(t, err)
+
+	compressed, err := compress(content)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: compressed})
+}
+
+func TestSigningDomain(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, issuerKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	verifier, err := NewVerifier(publicKey)
+	require.NoError(t, err)
+
+	certificate, err := (&IssuerCertificate{
+		PublicKey: issuerKey.Public().(ed25519.PublicKey),
+		ExpiredAt: 4102444800,
+		Features:  []string{"api.*", "core.*"},
+	}).Encode(privateKey)
+	require.NoError(t, err)
+
+	revocationList, err := (&RevocationList{IssuedAt: 1700000000}).Encode(privateKey)
+	require.NoError(t, err)
+
+	// renamed returns the PEM block with its type replaced by the license key type.
+	renamed := func(data []byte) []byte {
+		block, _ := pem.Decode(data)
+		require.NotNil(t, block)
+
+		block.Type = licenseBlockType
+
+		return pem.EncodeToMemory(block)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "issuer certificate", data: certificate},
+		{name: "revocation list", data: revocationList},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" as license key", func(t *testing.T) {
+			_, err := verifier.Verify(renamed(tt.data))
+			assert.ErrorIs(t, err, ErrVerifySignature)
+		})
+	}
+
+	t.Run("license key as another block", func(t *testing.T) {
+		encoded, err := (&License{ID: "domain-license"}).Encode(privateKey)
+		require.NoError(t, err)
+
+		block, _ := pem.Decode(encoded)
+		require.NotNil(t, block)
+
+		block.Type = revocationListBlockType
+
+		_, err = verifier.VerifyRevocationList(pem.EncodeToMemory(block))
+		assert.ErrorIs(t, err, ErrVerifySignature)
+	})
+
+	t.Run("signed document without license ID", func(t *testing.T) {
+		encoded, err := EncodeBlock(licenseBlockType, nil, []byte(`{"ftr":["api.*"]}`), privateKey)
+		require.NoError(t, err)
+
+		_, err = verifier.Verify(encoded)
+		assert.ErrorIs(t, err, ErrLicenseIDNotDefined)
+
+		var decodeErr *Error
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Equal(t, CodeClaimsInvalid, decodeErr.Code)
+	})
+}
+
+func TestMultiSignature(t *testing.T) {
+	billingPublicKey, billingPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	approverPublicKey, approverPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	otherPublicKey, otherPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{ID: "multi-signature-license"}
+
+	encoded, err := license.EncodeWithOptions(billingPrivateKey, EncodeOptions{
+		CoSigners: []crypto.Signer{approverPrivateKey},
+	})
+	require.NoError(t, err)
+
+	t.Run("all signers trusted", func(t *testing.T) {
+		decoded, err := DecodeWithOptions(encoded, DecodeOptions{Threshold: 2}, billingPublicKey, approverPublicKey)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+		assert.Equal(t, KeyID(billingPublicKey), decoded.SignedBy())
+		assert.Equal(t, []string{KeyID(billingPublicKey), KeyID(approverPublicKey)}, decoded.Signers())
+	})
+
+	t.Run("threshold not met", func(t *testing.T) {
+		_, err := DecodeWithOptions(encoded, DecodeOptions{Threshold: 2}, billingPublicKey, otherPublicKey)
+		assert.ErrorIs(t, err, ErrSignatureThreshold)
+
+		var licenseErr *Error
+		require.ErrorAs(t, err, &licenseErr)
+		assert.Equal(t, CodeSignatureThreshold, licenseErr.Code)
+		assert.Equal(t, LayerSignature, licenseErr.Layer)
+	})
+
+	t.Run("default threshold accepts one trusted signer", func(t *testing.T) {
+		decoded, err := Decode(encoded, approverPublicKey)
+		require.NoError(t, err)
+		assert.Equal(t, KeyID(approverPublicKey), decoded.SignedBy())
+		assert.Equal(t, []string{KeyID(approverPublicKey)}, decoded.Signers())
+	})
+
+	t.Run("no trusted signer", func(t *testing.T) {
+		_, err := Decode(encoded, otherPublicKey)
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("revoked primary key", func(t *testing.T) {
+		keyring := NewKeyring()
+		billingID, err := keyring.Add(billingPublicKey, KeyActive)
+		require.NoError(t, err)
+		_, err = keyring.Add(approverPublicKey, KeyActive)
+		require.NoError(t, err)
+		require.NoError(t, keyring.SetStatus(billingID, KeyRevoked))
+
+		verifier, err := NewKeyringVerifier(keyring)
+		require.NoError(t, err)
+
+		decoded, err := verifier.Verify(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, []string{KeyID(approverPublicKey)}, decoded.Signers())
+
+		_, err = verifier.WithThreshold(2).Verify(encoded)
+		assert.ErrorIs(t, err, ErrSignatureThreshold)
+
+		_, err = verifier.WithThreshold(2).WithDecodeOptions(DecodeOptions{MaxInputBytes: 1 << 20}).Verify(encoded)
+		assert.ErrorIs(t, err, ErrSignatureThreshold)
+	})
+
+	t.Run("same key counts once", func(t *testing.T) {
+		encoded, err := license.EncodeWithOptions(billingPrivateKey, EncodeOptions{
+			CoSigners: []crypto.Signer{billingPrivateKey, billingPrivateKey},
+		})
+		require.NoError(t, err)
+
+		decoded, err := DecodeWithOptions(encoded, DecodeOptions{Threshold: 2}, billingPublicKey)
+		assert.ErrorIs(t, err, ErrSignatureThreshold)
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("nil co-signer", func(t *testing.T) {
+		_, err := license.EncodeWithOptions(billingPrivateKey, EncodeOptions{
+			CoSigners: []crypto.Signer{nil},
+		})
+		assert.ErrorIs(t, err, ErrPrivateKeyNotDefined)
+	})
+
+	t.Run("invalid co-signature of a trusted key", func(t *testing.T) {
+		keyring := NewKeyring()
+		_, err := keyring.Add(billingPublicKey, KeyActive)
+		require.NoError(t, err)
+		_, err = keyring.Add(approverPublicKey, KeyActive)
+		require.NoError(t, err)
+
+		payload := []byte(`{"id":"multi-signature-license"}`)
+
+		content := licenseContent{
+			KeyID: KeyID(billingPublicKey),
+			Signatures: []signatureContent{{
+				KeyID: KeyID(approverPublicKey),
+				Sign:  base64.RawURLEncoding.EncodeToString(ed25519.Sign(otherPrivateKey, payload)),
+			}},
+		}
+
+		dec := decoder{keyring: keyring}
+
+		_, err = dec.verifySignatures(AlgorithmEd25519, content, payload, ed25519.Sign(billingPrivateKey, payload))
+		assert.ErrorIs(t, err, ErrVerifySignature)
+
+		var licenseErr *Error
+		require.ErrorAs(t, err, &licenseErr)
+		assert.Equal(t, CodeSignatureInvalid, licenseErr.Code)
+	})
+
+	t.Run("co-signature without key ID", func(t *testing.T) {
+		payload := []byte(`{"id":"multi-signature-license"}`)
+
+		content := licenseContent{
+			KeyID: KeyID(billingPublicKey),
+			Signatures: []signatureContent{{
+				Sign: base64.RawURLEncoding.EncodeToString(ed25519.Sign(approverPrivateKey, payload)),
+			}},
+		}
+
+		keyring, err := newDecodeKeyring([]ed25519.PublicKey{billingPublicKey})
+		require.NoError(t, err)
+
+		dec := decoder{keyring: keyring}
+
+		_, err = dec.verifySignatures(AlgorithmEd25519, content, payload, ed25519.Sign(billingPrivateKey, payload))
+		assert.ErrorIs(t, err, ErrMalformedLicense)
+	})
+
+	t.Run("single signature formats", func(t *testing.T) {
+		token, err := license.EncodeJWT(billingPrivateKey)
+		require.NoError(t, err)
+
+		verifier, err := NewVerifier(billingPublicKey, approverPublicKey)
+		require.NoError(t, err)
+
+		_, err = verifier.WithThreshold(2).VerifyJWT(token)
+		assert.ErrorIs(t, err, ErrSignatureThreshold)
+
+		key, err := license.EncodeCompact(billingPrivateKey)
+		require.NoError(t, err)
+
+		_, err = verifier.WithThreshold(2).VerifyCompact(key)
+		assert.ErrorIs(t, err, ErrSignatureThreshold)
+	})
+
+	t.Run("unverified decode", func(t *testing.T) {
+		decoded, err := DecodeUnverified(encoded)
+		require.NoError(t, err)
+		assert.Empty(t, decoded.Signers())
+		assert.Empty(t, decoded.SignedBy())
+	})
+}
+
+func TestVerifier_VerifyBlock_Threshold(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	coSignerPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	encoded, err := EncodeBlock("TEST BLOCK", nil, []byte("payload"), privateKey)
+	require.NoError(t, err)
+
+	verifier, err := NewVerifier(publicKey, coSignerPublicKey)
+	require.NoError(t, err)
+
+	_, _, err = verifier.WithThreshold(2).VerifyBlock(encoded, "TEST BLOCK")
+	assert.ErrorIs(t, err, ErrSignatureThreshold)
+
+	_, payload, err := verifier.VerifyBlock(encoded, "TEST BLOCK")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("payload"), payload)
+}
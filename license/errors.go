@@ -1,17 +1,206 @@
 package license
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	ErrMalformedLicense    = errors.New("malformed license")
 	ErrWrongVerifyChecksum = errors.New("wrong verify checksum")
 	ErrVerifySignature     = errors.New("error verify signature")
 	ErrWrongVerifyID       = errors.New("wrong verify id")
+	ErrUnknownKey          = errors.New("unknown key")
+	ErrKeyRevoked          = errors.New("key revoked")
+	ErrInvalidKeyStatus    = errors.New("invalid key status")
+	ErrSignatureThreshold  = errors.New("not enough trusted signatures")
+	ErrAlgorithmMismatch   = errors.New("key not valid for the signature algorithm")
+
+	ErrCertificateChain      = errors.New("invalid issuer certificate chain")
+	ErrCertificateConstraint = errors.New("license exceeds the issuer certificate constraints")
+
+	ErrUnsupportedVersion     = errors.New("unsupported envelope version")
+	ErrUnsupportedAlgorithm   = errors.New("unsupported signature algorithm")
+	ErrUnsupportedCompression = errors.New("unsupported compression")
+
+	ErrNotDecoded = errors.New("license not decoded from a license key")
 
 	ErrLicenseIDNotDefined  = errors.New("license id not defined")
 	ErrTime                 = errors.New("the expire time must be greater than the issue time")
+	ErrNotBeforeTime        = errors.New("the expire time must be greater than the not before time")
+	ErrGracePeriod          = errors.New("the grace period must not be negative")
+	ErrMachineBinding       = errors.New("the machine binding must have components and a threshold not above their count")
+	ErrEntitlementName      = errors.New("feature and limit names must be dot-separated, with a wildcard only as the last part")
 	ErrPrivateKeyNotDefined = errors.New("private key not defined")
+	ErrPublicKeyNotDefined  = errors.New("public key not defined")
+	ErrInvalidPublicKey     = errors.New("invalid public key")
 
 	ErrInvalidKey   = errors.New("invalid key")
 	ErrInvalidNonce = errors.New("invalid nonce")
+
+	ErrInvalidRecipientKey  = errors.New("invalid recipient key")
+	ErrRecipientKeyNotFound = errors.New("recipient key not found")
+
+	ErrLimitExceeded = errors.New("decode limit exceeded")
+
+	ErrCompactTooLarge    = errors.New("license too large for the compact format")
+	ErrCompactCharacter   = errors.New("invalid character")
+	ErrCompactGroupLength = errors.New("wrong number of characters")
+	ErrCompactChecksum    = errors.New("check character mismatch")
+
+	ErrDataNotDefined = errors.New("license data not defined")
+	ErrInvalidData    = errors.New("invalid license data")
+
+	ErrExpired         = errors.New("license expired")
+	ErrIssuedInFuture  = errors.New("license issued in the future")
+	ErrNotYetValid     = errors.New("license not yet valid")
+	ErrMissingClaim    = errors.New("missing required claim")
+	ErrMachineMismatch = errors.New("license bound to another machine")
+
+	ErrLicenseRevoked  = errors.New("license revoked")
+	ErrRevocationEntry = errors.New("revocation entry must have a license id or fingerprint")
+)
+
+// LimitError is returned when a license exceeds one of the DecodeOptions limits.
+// It matches ErrLimitExceeded with errors.Is.
+type LimitError struct {
+	Limit string // input, decompressed, data or headers
+	Max   int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("license %s exceeds the limit of %d", e.Limit, e.Max)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// RevokedError is returned when a license is listed in a revocation list.
+// It matches ErrLicenseRevoked with errors.Is.
+type RevokedError struct {
+	Entry RevocationEntry
+}
+
+func (e *RevokedError) Error() string {
+	if len(e.Entry.Reason) == 0 {
+		return ErrLicenseRevoked.Error()
+	}
+
+	return fmt.Sprintf("%s: %s", ErrLicenseRevoked, e.Entry.Reason)
+}
+
+func (e *RevokedError) Unwrap() error {
+	return ErrLicenseRevoked
+}
+
+// ValidationError lists every check that failed during License.Validate.
+// Each failure can be matched with errors.Is, e.g. errors.Is(err, ErrExpired).
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+
+	return "license validation failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+// ErrorCode is a stable machine readable code of a license decoding failure.
+type ErrorCode string
+
+const (
+	CodeMalformed             ErrorCode = "LICENSE_MALFORMED"
+	CodeUnsupportedFormat     ErrorCode = "LICENSE_UNSUPPORTED_FORMAT"
+	CodeCorrupted             ErrorCode = "LICENSE_CORRUPTED"
+	CodeTypo                  ErrorCode = "LICENSE_TYPO"
+	CodeTooLarge              ErrorCode = "LICENSE_TOO_LARGE"
+	CodeDecryptionFailed      ErrorCode = "LICENSE_DECRYPTION_FAILED"
+	CodeRecipientKeyNotFound  ErrorCode = "LICENSE_RECIPIENT_KEY_NOT_FOUND"
+	CodeChecksumMismatch      ErrorCode = "LICENSE_CHECKSUM_MISMATCH"
+	CodeSignatureInvalid      ErrorCode = "LICENSE_SIGNATURE_INVALID"
+	CodeSignatureThreshold    ErrorCode = "LICENSE_SIGNATURE_THRESHOLD"
+	CodeCertificateInvalid    ErrorCode = "LICENSE_CERTIFICATE_INVALID"
+	CodeCertificateConstraint ErrorCode = "LICENSE_CERTIFICATE_CONSTRAINT"
+	CodeUnknownKey            ErrorCode = "LICENSE_UNKNOWN_KEY"
+	CodeKeyRevoked            ErrorCode = "LICENSE_KEY_REVOKED"
+	CodeClaimsInvalid         ErrorCode = "LICENSE_CLAIMS_INVALID"
+	CodeIDMismatch            ErrorCode = "LICENSE_ID_MISMATCH"
 )
+
+// Layer is the part of the license key format in which decoding failed.
+type Layer string
+
+const (
+	LayerPEM         Layer = "pem" // PEM block, compact key groups or JWT segments
+	LayerCompression Layer = "compression"
+	LayerEnvelope    Layer = "envelope"
+	LayerDecryption  Layer = "decryption"
+	LayerChecksum    Layer = "checksum"
+	LayerSignature   Layer = "signature"
+	LayerClaims      Layer = "claims"
+)
+
+// errorMessages are the end-user messages of the error codes.
+var errorMessages = map[ErrorCode]string{
+	CodeMalformed:             "The license key is not valid. Copy the complete key, including the BEGIN and END lines.",
+	CodeUnsupportedFormat:     "The license key requires a newer version of the product.",
+	CodeCorrupted:             "The license key is damaged. Copy it again from the original message.",
+	CodeTypo:                  "The license key has a typo.",
+	CodeTooLarge:              "The license key is too large.",
+	CodeDecryptionFailed:      "The license key could not be decrypted.",
+	CodeRecipientKeyNotFound:  "The license key was issued for another installation.",
+	CodeChecksumMismatch:      "The license key is damaged. Copy it again from the original message.",
+	CodeSignatureInvalid:      "The license key is not genuine.",
+	CodeSignatureThreshold:    "The license key is missing a required approval.",
+	CodeCertificateInvalid:    "The license key was issued by an unauthorized issuer.",
+	CodeCertificateConstraint: "The license key grants more than its issuer is allowed to.",
+	CodeUnknownKey:            "The license key was issued for another product.",
+	CodeKeyRevoked:            "The license key was signed with a key that is no longer trusted.",
+	CodeClaimsInvalid:         "The license key contains invalid license data.",
+	CodeIDMismatch:            "The license key has been modified.",
+}
+
+// Error is returned when a license key fails to decode. Code and Layer tell a corrupted key
+// from a forged one or one issued for another product, Message is safe to show to end users
+// and Err is the underlying cause, which still matches the sentinel errors with errors.Is.
+type Error struct {
+	Code    ErrorCode
+	Layer   Layer
+	Message string
+	Err     error
+}
+
+func newError(code ErrorCode, layer Layer, err error) *Error {
+	return &Error{
+		Code:    code,
+		Layer:   layer,
+		Message: errorMessages[code],
+		Err:     err,
+	}
+}
+
+// malformedError wraps a low-level decoding error so that it also matches ErrMalformedLicense.
+func malformedError(code ErrorCode, layer Layer, err error) *Error {
+	if errors.Is(err, ErrMalformedLicense) {
+		return newError(code, layer, err)
+	}
+
+	return newError(code, layer, fmt.Errorf("%w: %w", ErrMalformedLicense, err))
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%s): %v", e.Code, e.Layer, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
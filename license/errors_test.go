@@ -0,0 +1,215 @@
+package license
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError(t *testing.T) {
+	err := newError(CodeChecksumMismatch, LayerChecksum, ErrWrongVerifyChecksum)
+
+	assert.Equal(t, "LICENSE_CHECKSUM_MISMATCH (checksum): wrong verify checksum", err.Error())
+	assert.Equal(t, errorMessages[CodeChecksumMismatch], err.Message)
+	assert.ErrorIs(t, err, ErrWrongVerifyChecksum)
+
+	t.Run("malformed cause", func(t *testing.T) {
+		cause := errors.New("unexpected EOF")
+		err := malformedError(CodeCorrupted, LayerCompression, cause)
+
+		assert.ErrorIs(t, err, ErrMalformedLicense)
+		assert.ErrorIs(t, err, cause)
+	})
+
+	t.Run("every code has a message", func(t *testing.T) {
+		for code, message := range errorMessages {
+			assert.NotEmpty(t, message, code)
+		}
+	})
+}
+
+func TestDecode_ErrorCodes(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	recipientKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{ID: "error-license"}
+
+	encoded, err := license.Encode(privateKey)
+	require.NoError(t, err)
+
+	// rewrite decodes the envelope of the encoded license, changes it and encodes it again.
+	rewrite := func(change func(block *pem.Block, content *licenseContent)) []byte {
+		block, _ := pem.Decode(encoded)
+		require.NotNil(t, block)
+
+		decompressed, err := decompress(block.Bytes)
+		require.NoError(t, err)
+
+		var content licenseContent
+		require.NoError(t, json.Unmarshal(decompressed, &content))
+
+		change(block, &content)
+
+		data, err := json.Marshal(content)
+		require.NoError(t, err)
+
+		block.Bytes, err = compress(data)
+		require.NoError(t, err)
+
+		return pem.EncodeToMemory(block)
+	}
+
+	encrypted, err := license.EncodeWithOptions(privateKey, EncodeOptions{Recipients: []*ecdh.PublicKey{recipientKey.PublicKey()}})
+	require.NoError(t, err)
+
+	revoked := NewKeyring()
+	_, err = revoked.Add(publicKey, KeyRevoked)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		decode   func() error
+		code     ErrorCode
+		layer    Layer
+		sentinel error
+	}{
+		{
+			name:     "not a PEM block",
+			decode:   func() error { _, err := Decode([]byte("license"), publicKey); return err },
+			code:     CodeMalformed,
+			layer:    LayerPEM,
+			sentinel: ErrMalformedLicense,
+		},
+		{
+			name: "too large",
+			decode: func() error {
+				_, err := DecodeWithOptions(encoded, DecodeOptions{MaxInputBytes: 16}, publicKey)
+				return err
+			},
+			code:     CodeTooLarge,
+			layer:    LayerPEM,
+			sentinel: ErrLimitExceeded,
+		},
+		{
+			name: "corrupted compression",
+			decode: func() error {
+				_, err := Decode(pem.EncodeToMemory(&pem.Block{Type: licenseBlockType, Bytes: []byte("corrupted")}), publicKey)
+				return err
+			},
+			code:     CodeCorrupted,
+			layer:    LayerCompression,
+			sentinel: ErrMalformedLicense,
+		},
+		{
+			name: "corrupted encoding",
+			decode: func() error {
+				_, err := Decode(rewrite(func(_ *pem.Block, content *licenseContent) { content.Sign = "!!!" }), publicKey)
+				return err
+			},
+			code:     CodeCorrupted,
+			layer:    LayerEnvelope,
+			sentinel: ErrMalformedLicense,
+		},
+		{
+			name: "tampered hash",
+			decode: func() error {
+				_, err := Decode(rewrite(func(_ *pem.Block, content *licenseContent) { content.DataHash = content.Sign }), publicKey)
+				return err
+			},
+			code:  CodeDecryptionFailed,
+			layer: LayerDecryption,
+		},
+		{
+			name: "checksum mismatch",
+			decode: func() error {
+				_, err := Decode(rewrite(func(_ *pem.Block, content *licenseContent) {
+					signature, _ := base64.RawURLEncoding.DecodeString(content.Sign)
+					hash := sha256.Sum256([]byte("other"))
+
+					data, err := encryptData([]byte(`{"id":"error-license"}`), signature, hash[:])
+					require.NoError(t, err)
+
+					content.Data = base64.RawURLEncoding.EncodeToString(data)
+					content.DataHash = base64.RawURLEncoding.EncodeToString(hash[:])
+				}), publicKey)
+				return err
+			},
+			code:     CodeChecksumMismatch,
+			layer:    LayerChecksum,
+			sentinel: ErrWrongVerifyChecksum,
+		},
+		{
+			name: "forged signature",
+			decode: func() error {
+				forged := rewrite(func(_ *pem.Block, content *licenseContent) { content.KeyID = KeyID(otherPublicKey) })
+				_, err := Decode(forged, otherPublicKey)
+				return err
+			},
+			code:     CodeSignatureInvalid,
+			layer:    LayerSignature,
+			sentinel: ErrVerifySignature,
+		},
+		{
+			name:     "another product key",
+			decode:   func() error { _, err := Decode(encoded, otherPublicKey); return err },
+			code:     CodeUnknownKey,
+			layer:    LayerSignature,
+			sentinel: ErrUnknownKey,
+		},
+		{
+			name:     "revoked key",
+			decode:   func() error { _, err := revoked.Decode(encoded); return err },
+			code:     CodeKeyRevoked,
+			layer:    LayerSignature,
+			sentinel: ErrKeyRevoked,
+		},
+		{
+			name:     "another installation",
+			decode:   func() error { _, err := Decode(encrypted, publicKey); return err },
+			code:     CodeRecipientKeyNotFound,
+			layer:    LayerDecryption,
+			sentinel: ErrRecipientKeyNotFound,
+		},
+		{
+			name: "modified id header",
+			decode: func() error {
+				_, err := Decode(rewrite(func(block *pem.Block, _ *licenseContent) { block.Headers["id"] = "other" }), publicKey)
+				return err
+			},
+			code:     CodeIDMismatch,
+			layer:    LayerClaims,
+			sentinel: ErrWrongVerifyID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.decode()
+
+			var licenseErr *Error
+			require.ErrorAs(t, err, &licenseErr)
+			assert.Equal(t, tt.code, licenseErr.Code)
+			assert.Equal(t, tt.layer, licenseErr.Layer)
+			assert.NotEmpty(t, licenseErr.Message)
+
+			if tt.sentinel != nil {
+				assert.ErrorIs(t, err, tt.sentinel)
+			}
+		})
+	}
+}
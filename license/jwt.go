@@ -0,0 +1,206 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const jwtAlgorithmEdDSA = "EdDSA"
+
+// jwtType is the explicit "typ" of license JWTs (RFC 8725), so other JWTs signed by the same key,
+// such as access tokens, are not accepted as licenses.
+const jwtType = "license+jwt"
+
+// jwtHeader is the JOSE header of a license JWT.
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// EncodeJWT signs the license with the ed25519 private key and returns it as a compact JWS
+// (RFC 7515) with the EdDSA algorithm (RFC 8037). The claims are those of License, with the
+// license ID as the standard "jti" claim, so standard JWT tooling can verify the token and
+// check its "exp", "nbf" and "iat" claims.
+func (lic *License) EncodeJWT(privateKey ed25519.PrivateKey) (string, error) {
+	if err := lic.validateClaims(); err != nil {
+		return "", err
+	}
+
+	if privateKey == nil {
+		return "", ErrPrivateKeyNotDefined
+	}
+
+	header, err := json.Marshal(jwtHeader{
+		Algorithm: jwtAlgorithmEdDSA,
+		Type:      jwtType,
+		KeyID:     KeyID(privateKey.Public().(ed25519.PublicKey)),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := lic.jwtClaims()
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	signature := ed25519.Sign(privateKey, []byte(signingInput))
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// DecodeJWT decodes a license JWT encoded with EncodeJWT and verifies the signature using the ed25519
+// public keys. At least one public key is required. Only the EdDSA algorithm is accepted.
+func DecodeJWT(token string, publicKeys ...ed25519.PublicKey) (*License, error) {
+	keyring, err := newDecodeKeyring(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyring == nil || keyring.Len() == 0 {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	dec := decoder{keyring: keyring}
+
+	return dec.decodeJWT(token)
+}
+
+// DecodeJWTUnverified decodes a license JWT without verifying the signature.
+// It is intended for inspection tools and must not be used to grant access.
+func DecodeJWTUnverified(token string) (*License, error) {
+	dec := decoder{}
+
+	return dec.decodeJWT(token)
+}
+
+// VerifyJWT decodes the license JWT and verifies the signature.
+func (v *Verifier) VerifyJWT(token string) (*License, error) {
+	if !v.ready() {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	lic, err := v.dec.decodeJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.checkRevoked(lic)
+}
+
+func (d *decoder) decodeJWT(token string) (*License, error) {
+	limits := d.opts.withDefaults()
+
+	if exceeds(int64(len(token)), limits.MaxInputBytes) {
+		return nil, newError(CodeTooLarge, LayerPEM, &LimitError{Limit: "input", Max: limits.MaxInputBytes})
+	}
+
+	token = strings.TrimSpace(token)
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, newError(CodeMalformed, LayerPEM, ErrMalformedLicense)
+	}
+
+	headerData, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, malformedError(CodeCorrupted, LayerEnvelope, err)
+	}
+
+	var header jwtHeader
+	if err := json.Unmarshal(headerData, &header); err != nil {
+		return nil, malformedError(CodeCorrupted, LayerEnvelope, err)
+	}
+
+	if header.Algorithm != jwtAlgorithmEdDSA {
+		return nil, newError(CodeUnsupportedFormat, LayerEnvelope, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, header.Algorithm))
+	}
+
+	// Media type names are case-insensitive and the "application/" prefix may be omitted (RFC 7515).
+	if !strings.EqualFold(strings.TrimPrefix(strings.ToLower(header.Type), "application/"), jwtType) {
+		return nil, newError(CodeUnsupportedFormat, LayerEnvelope, fmt.Errorf("%w: type %q", ErrUnsupportedVersion, header.Type))
+	}
+
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, malformedError(CodeCorrupted, LayerEnvelope, err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, malformedError(CodeCorrupted, LayerEnvelope, err)
+	}
+
+	signingInput := token[:len(parts[0])+1+len(parts[1])]
+
+	var signers []string
+
+	if d.keyring != nil {
+		keyID, err := d.keyring.verify(AlgorithmEd25519, header.KeyID, []byte(signingInput), signature)
+		if err != nil {
+			return nil, signatureError(err)
+		}
+
+		signers = []string{keyID}
+	}
+
+	payload, err := licenseClaimsFromJWT(claims)
+	if err != nil {
+		return nil, malformedError(CodeClaimsInvalid, LayerClaims, err)
+	}
+
+	license, err := d.decodeClaims(payload, signers)
+	if err != nil {
+		return nil, err
+	}
+
+	license.signed = []byte(signingInput)
+
+	return license, nil
+}
+
+// jwtClaims returns the license claims with the license ID as the "jti" claim.
+func (lic *License) jwtClaims() ([]byte, error) {
+	payload, err := json.Marshal(lic)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, err
+	}
+
+	if id, ok := claims["id"]; ok {
+		delete(claims, "id")
+		claims["jti"] = id
+	}
+
+	return json.Marshal(claims)
+}
+
+// licenseClaimsFromJWT returns the license claims of JWT claims, with the "jti" claim as the license ID.
+func licenseClaimsFromJWT(data []byte) ([]byte, error) {
+	var claims map[string]json.RawMessage
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, err
+	}
+
+	if _, ok := claims["id"]; ok {
+		return nil, errors.New("unexpected id claim, the license ID is the jti claim")
+	}
+
+	if id, ok := claims["jti"]; ok {
+		delete(claims, "jti")
+		claims["id"] = id
+	}
+
+	return json.Marshal(claims)
+}
@@ -0,0 +1,224 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeJWT_DecodeJWT(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{
+		ID:           "jwt-license",
+		Customer:     "customer-123",
+		Subscription: "sub-456",
+		IssuedAt:     1700000000,
+		NotBefore:    1700000000,
+		ExpiredAt:    1800000000,
+		Features:     []string{"api"},
+		Data:         json.RawMessage(`{"org":"ACME"}`),
+	}
+
+	token, err := license.EncodeJWT(privateKey)
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	t.Run("standard jws", func(t *testing.T) {
+		header, err := base64.RawURLEncoding.DecodeString(parts[0])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"alg":"EdDSA","typ":"license+jwt","kid":"`+KeyID(publicKey)+`"}`, string(header))
+
+		claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"jti": "jwt-license",
+			"cus": "customer-123",
+			"sub": "sub-456",
+			"iat": 1700000000,
+			"nbf": 1700000000,
+			"exp": 1800000000,
+			"ftr": ["api"],
+			"dat": {"org": "ACME"}
+		}`, string(claims))
+
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+		assert.True(t, ed25519.Verify(publicKey, []byte(parts[0]+"."+parts[1]), signature))
+	})
+
+	t.Run("roundtrip", func(t *testing.T) {
+		decoded, err := DecodeJWT(token, publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+		assert.Equal(t, license.Customer, decoded.Customer)
+		assert.Equal(t, license.ExpiredAt, decoded.ExpiredAt)
+		assert.JSONEq(t, string(license.Data), string(decoded.Data))
+		assert.Equal(t, KeyID(publicKey), decoded.SignedBy())
+
+		fingerprint, err := license.GetFingerprint()
+		require.NoError(t, err)
+
+		decodedFingerprint, err := decoded.GetFingerprint()
+		require.NoError(t, err)
+		assert.Equal(t, fingerprint, decodedFingerprint)
+
+		signed, err := decoded.SignedFingerprint()
+		require.NoError(t, err)
+		assert.Equal(t, fingerprintOf([]byte(parts[0]+"."+parts[1])), signed)
+	})
+
+	t.Run("unverified", func(t *testing.T) {
+		decoded, err := DecodeJWTUnverified(token)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+		assert.Empty(t, decoded.SignedBy())
+	})
+
+	t.Run("verifier", func(t *testing.T) {
+		verifier, err := NewVerifier(publicKey)
+		require.NoError(t, err)
+
+		decoded, err := verifier.VerifyJWT(token)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+
+		_, err = verifier.WithRevocationList(&RevocationList{Entries: []RevocationEntry{{ID: license.ID}}}).VerifyJWT(token)
+		assert.ErrorIs(t, err, ErrLicenseRevoked)
+
+		_, err = (&Verifier{}).VerifyJWT(token)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("invalid claims", func(t *testing.T) {
+		_, err := (&License{ID: "jwt-license", IssuedAt: 2, ExpiredAt: 1}).EncodeJWT(privateKey)
+		assert.ErrorIs(t, err, ErrTime)
+
+		_, err = license.EncodeJWT(nil)
+		assert.ErrorIs(t, err, ErrPrivateKeyNotDefined)
+	})
+}
+
+func TestDecodeJWT_Errors(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	token, err := (&License{ID: "jwt-license"}).EncodeJWT(privateKey)
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+
+	encode := func(data string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(data))
+	}
+
+	// sign returns a token with the header and claims signed by the private key.
+	sign := func(header, claims string) string {
+		signingInput := encode(header) + "." + encode(claims)
+		return signingInput + "." + base64.RawURLEncoding.EncodeToString(ed25519.Sign(privateKey, []byte(signingInput)))
+	}
+
+	tests := []struct {
+		name        string
+		token       string
+		keys        []ed25519.PublicKey
+		expectedErr error
+	}{
+		{
+			name:        "not a jwt",
+			token:       "license",
+			keys:        []ed25519.PublicKey{publicKey},
+			expectedErr: ErrMalformedLicense,
+		},
+		{
+			name:        "none algorithm",
+			token:       encode(`{"alg":"none"}`) + "." + parts[1] + ".",
+			keys:        []ed25519.PublicKey{publicKey},
+			expectedErr: ErrUnsupportedAlgorithm,
+		},
+		{
+			name:        "other algorithm",
+			token:       sign(`{"alg":"HS256"}`, `{"jti":"jwt-license"}`),
+			keys:        []ed25519.PublicKey{publicKey},
+			expectedErr: ErrUnsupportedAlgorithm,
+		},
+		{
+			name:        "corrupted header",
+			token:       "!!!." + parts[1] + "." + parts[2],
+			keys:        []ed25519.PublicKey{publicKey},
+			expectedErr: ErrMalformedLicense,
+		},
+		{
+			name:        "modified claims",
+			token:       parts[0] + "." + encode(`{"jti":"other-license"}`) + "." + parts[2],
+			keys:        []ed25519.PublicKey{publicKey},
+			expectedErr: ErrVerifySignature,
+		},
+		{
+			name:        "other product key",
+			token:       token,
+			keys:        []ed25519.PublicKey{otherPublicKey},
+			expectedErr: ErrUnknownKey,
+		},
+		{
+			name:        "other token type",
+			token:       sign(`{"alg":"EdDSA","typ":"JWT"}`, `{"jti":"jwt-license"}`),
+			keys:        []ed25519.PublicKey{publicKey},
+			expectedErr: ErrUnsupportedVersion,
+		},
+		{
+			name:        "missing token type",
+			token:       sign(`{"alg":"EdDSA"}`, `{"jti":"jwt-license"}`),
+			keys:        []ed25519.PublicKey{publicKey},
+			expectedErr: ErrUnsupportedVersion,
+		},
+		{
+			name:        "signed token without license ID",
+			token:       sign(`{"alg":"EdDSA","typ":"license+jwt"}`, `{"sub":"user@example.com","scope":"admin"}`),
+			keys:        []ed25519.PublicKey{publicKey},
+			expectedErr: ErrLicenseIDNotDefined,
+		},
+		{
+			name:        "id claim",
+			token:       sign(`{"alg":"EdDSA","typ":"license+jwt"}`, `{"id":"jwt-license"}`),
+			keys:        []ed25519.PublicKey{publicKey},
+			expectedErr: ErrMalformedLicense,
+		},
+		{
+			name:        "claims not an object",
+			token:       sign(`{"alg":"EdDSA","typ":"license+jwt"}`, `[]`),
+			keys:        []ed25519.PublicKey{publicKey},
+			expectedErr: ErrMalformedLicense,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeJWT(tt.token, tt.keys...)
+			assert.ErrorIs(t, err, tt.expectedErr)
+
+			var licenseErr *Error
+			assert.ErrorAs(t, err, &licenseErr)
+		})
+	}
+
+	t.Run("no public keys", func(t *testing.T) {
+		_, err := DecodeJWT(token)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+
+		_, err = DecodeJWT(token, nil)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+}
@@ -0,0 +1,231 @@
+package license
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sync"
+)
+
+// KeyStatus is the lifecycle state of a key in a Keyring.
+type KeyStatus int
+
+const (
+	// KeyActive keys are used to issue new licenses and verify existing ones.
+	KeyActive KeyStatus = iota + 1
+	// KeyRetired keys no longer issue licenses, but licenses they signed remain valid.
+	KeyRetired
+	// KeyRevoked keys are compromised; licenses they signed are rejected.
+	KeyRevoked
+)
+
+func (s KeyStatus) String() string {
+	switch s {
+	case KeyActive:
+		return "active"
+	case KeyRetired:
+		return "retired"
+	case KeyRevoked:
+		return "revoked"
+	default:
+		return fmt.Sprintf("KeyStatus(%d)", int(s))
+	}
+}
+
+// KeyID returns the identifier of the ed25519 public key carried in encoded licenses.
+// It is the base64 encoded first 16 bytes of the SHA-256 hash of the key.
+func KeyID(publicKey ed25519.PublicKey) string {
+	return keyIDFromBytes(publicKey)
+}
+
+func keyIDFromBytes(publicKey []byte) string {
+	hash := sha256.Sum256(publicKey)
+
+	return base64.RawURLEncoding.EncodeToString(hash[:16])
+}
+
+// Key is a public key registered in a Keyring. It only verifies signatures of its algorithm.
+type Key struct {
+	ID        string
+	Algorithm Algorithm
+	PublicKey crypto.PublicKey
+	Status    KeyStatus
+}
+
+// Keyring maps key IDs to public keys with their status.
+// It is safe for concurrent use, so statuses can be changed while verifiers are in use.
+type Keyring struct {
+	mu    sync.RWMutex
+	keys  map[string]*Key
+	order []string
+}
+
+// NewKeyring returns an empty Keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{
+		keys: make(map[string]*Key),
+	}
+}
+
+// Add registers the ed25519 public key with the given status and returns its key ID.
+// Adding a key that is already present updates its status.
+func (kr *Keyring) Add(publicKey ed25519.PublicKey, status KeyStatus) (string, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return "", ErrInvalidPublicKey
+	}
+
+	return kr.AddKey(AlgorithmEd25519, append(ed25519.PublicKey(nil), publicKey...), status)
+}
+
+// AddKey registers the public key of the signature algorithm with the given status and returns its key ID.
+// Adding a key that is already present updates its status, unless it is registered for another algorithm.
+func (kr *Keyring) AddKey(alg Algorithm, publicKey crypto.PublicKey, status KeyStatus) (string, error) {
+	if alg == nil {
+		return "", ErrUnsupportedAlgorithm
+	}
+
+	keyID, err := algorithmKeyID(alg, publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	if !status.valid() {
+		return "", ErrInvalidKeyStatus
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	if key, ok := kr.keys[keyID]; ok {
+		if key.Algorithm.Name() != alg.Name() {
+			return "", ErrAlgorithmMismatch
+		}
+
+		key.Status = status
+		return keyID, nil
+	}
+
+	kr.keys[keyID] = &Key{
+		ID:        keyID,
+		Algorithm: alg,
+		PublicKey: publicKey,
+		Status:    status,
+	}
+	kr.order = append(kr.order, keyID)
+
+	return keyID, nil
+}
+
+// SetStatus changes the status of the key with the given ID.
+func (kr *Keyring) SetStatus(keyID string, status KeyStatus) error {
+	if !status.valid() {
+		return ErrInvalidKeyStatus
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	key, ok := kr.keys[keyID]
+	if !ok {
+		return ErrUnknownKey
+	}
+
+	key.Status = status
+
+	return nil
+}
+
+// Get returns the key with the given ID.
+func (kr *Keyring) Get(keyID string) (Key, bool) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	key, ok := kr.keys[keyID]
+	if !ok {
+		return Key{}, false
+	}
+
+	return *key, true
+}
+
+// Keys returns all keys in the order they were added.
+func (kr *Keyring) Keys() []Key {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	keys := make([]Key, 0, len(kr.order))
+	for _, keyID := range kr.order {
+		keys = append(keys, *kr.keys[keyID])
+	}
+
+	return keys
+}
+
+// Len returns the number of keys in the keyring.
+func (kr *Keyring) Len() int {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	return len(kr.order)
+}
+
+// Decode decodes the PEM encoded license key and verifies the content signature using the key
+// referenced by the license key ID. The ID of the signing key is available from License.SignedBy.
+// It returns ErrPublicKeyNotDefined for a nil or empty keyring.
+func (kr *Keyring) Decode(data []byte) (*License, error) {
+	if kr == nil || kr.Len() == 0 {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	dec := decoder{keyring: kr}
+
+	return dec.decode(data)
+}
+
+// verify checks the signature of the algorithm with the key referenced by keyID and returns the key ID
+// that verified it. A key never verifies a signature of another algorithm. Licenses issued before key
+// IDs were introduced are checked against every key of the algorithm that is not revoked.
+func (kr *Keyring) verify(alg Algorithm, keyID string, message, sig []byte) (string, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	if keyID != "" {
+		key, ok := kr.keys[keyID]
+		if !ok {
+			return "", fmt.Errorf("%w: %w", ErrVerifySignature, ErrUnknownKey)
+		}
+
+		if key.Status == KeyRevoked {
+			return "", fmt.Errorf("%w: %w", ErrVerifySignature, ErrKeyRevoked)
+		}
+
+		if key.Algorithm.Name() != alg.Name() {
+			return "", fmt.Errorf("%w: %w", ErrVerifySignature, ErrAlgorithmMismatch)
+		}
+
+		if !key.Algorithm.Verify(key.PublicKey, message, sig) {
+			return "", ErrVerifySignature
+		}
+
+		return key.ID, nil
+	}
+
+	for _, id := range kr.order {
+		key := kr.keys[id]
+		if key.Status == KeyRevoked || key.Algorithm.Name() != alg.Name() {
+			continue
+		}
+
+		if key.Algorithm.Verify(key.PublicKey, message, sig) {
+			return key.ID, nil
+		}
+	}
+
+	return "", ErrVerifySignature
+}
+
+func (s KeyStatus) valid() bool {
+	return s >= KeyActive && s <= KeyRevoked
+}
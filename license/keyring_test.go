@@ -0,0 +1,245 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyID(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keyID := KeyID(publicKey)
+	assert.Equal(t, keyID, KeyID(publicKey))
+
+	decoded, err := base64.RawURLEncoding.DecodeString(keyID)
+	require.NoError(t, err)
+	assert.Len(t, decoded, 16)
+
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	assert.NotEqual(t, keyID, KeyID(otherPublicKey))
+}
+
+func TestKeyStatus_String(t *testing.T) {
+	tests := []struct {
+		status   KeyStatus
+		expected string
+	}{
+		{KeyActive, "active"},
+		{KeyRetired, "retired"},
+		{KeyRevoked, "revoked"},
+		{KeyStatus(0), "KeyStatus(0)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.status.String())
+		})
+	}
+}
+
+func TestKeyring(t *testing.T) {
+	t.Run("add and get", func(t *testing.T) {
+		publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		keyring := NewKeyring()
+		keyID, err := keyring.Add(publicKey, KeyActive)
+		require.NoError(t, err)
+		assert.Equal(t, KeyID(publicKey), keyID)
+
+		key, ok := keyring.Get(keyID)
+		require.True(t, ok)
+		assert.Equal(t, publicKey, key.PublicKey)
+		assert.Equal(t, KeyActive, key.Status)
+
+		_, ok = keyring.Get("unknown")
+		assert.False(t, ok)
+	})
+
+	t.Run("add existing key updates status", func(t *testing.T) {
+		publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		keyring := NewKeyring()
+		_, err = keyring.Add(publicKey, KeyActive)
+		require.NoError(t, err)
+
+		keyID, err := keyring.Add(publicKey, KeyRetired)
+		require.NoError(t, err)
+		assert.Equal(t, 1, keyring.Len())
+
+		key, ok := keyring.Get(keyID)
+		require.True(t, ok)
+		assert.Equal(t, KeyRetired, key.Status)
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		keyring := NewKeyring()
+
+		_, err = keyring.Add(nil, KeyActive)
+		assert.ErrorIs(t, err, ErrInvalidPublicKey)
+
+		_, err = keyring.Add(publicKey, KeyStatus(0))
+		assert.ErrorIs(t, err, ErrInvalidKeyStatus)
+
+		assert.ErrorIs(t, keyring.SetStatus("unknown", KeyRevoked), ErrUnknownKey)
+		assert.ErrorIs(t, keyring.SetStatus("unknown", KeyStatus(42)), ErrInvalidKeyStatus)
+	})
+
+	t.Run("keys keep insertion order", func(t *testing.T) {
+		keyring := NewKeyring()
+
+		var expected []string
+
+		for range 3 {
+			publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+			require.NoError(t, err)
+
+			keyID, err := keyring.Add(publicKey, KeyActive)
+			require.NoError(t, err)
+
+			expected = append(expected, keyID)
+		}
+
+		keys := keyring.Keys()
+		require.Len(t, keys, 3)
+
+		for i, key := range keys {
+			assert.Equal(t, expected[i], key.ID)
+		}
+	})
+}
+
+func TestKeyring_Decode(t *testing.T) {
+	oldPublicKey, oldPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	newPublicKey, newPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{
+		ID:        "keyring-license",
+		IssuedAt:  time.Now().Unix(),
+		ExpiredAt: time.Now().Add(time.Hour).Unix(),
+	}
+
+	oldEncoded, err := license.Encode(oldPrivateKey)
+	require.NoError(t, err)
+
+	newEncoded, err := license.Encode(newPrivateKey)
+	require.NoError(t, err)
+
+	keyring := NewKeyring()
+	oldKeyID, err := keyring.Add(oldPublicKey, KeyRetired)
+	require.NoError(t, err)
+
+	newKeyID, err := keyring.Add(newPublicKey, KeyActive)
+	require.NoError(t, err)
+
+	t.Run("active key", func(t *testing.T) {
+		decoded, err := keyring.Decode(newEncoded)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+		assert.Equal(t, newKeyID, decoded.SignedBy())
+	})
+
+	t.Run("retired key", func(t *testing.T) {
+		decoded, err := keyring.Decode(oldEncoded)
+		require.NoError(t, err)
+		assert.Equal(t, oldKeyID, decoded.SignedBy())
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, otherPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		encoded, err := license.Encode(otherPrivateKey)
+		require.NoError(t, err)
+
+		_, err = keyring.Decode(encoded)
+		assert.ErrorIs(t, err, ErrUnknownKey)
+		assert.ErrorIs(t, err, ErrVerifySignature)
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		revoked := NewKeyring()
+		_, err := revoked.Add(oldPublicKey, KeyRevoked)
+		require.NoError(t, err)
+
+		_, err = revoked.Decode(oldEncoded)
+		assert.ErrorIs(t, err, ErrKeyRevoked)
+		assert.ErrorIs(t, err, ErrVerifySignature)
+	})
+
+	t.Run("license without key id", func(t *testing.T) {
+		legacy := stripKeyID(t, oldEncoded)
+
+		decoded, err := keyring.Decode(legacy)
+		require.NoError(t, err)
+		assert.Equal(t, oldKeyID, decoded.SignedBy())
+
+		revoked := NewKeyring()
+		_, err = revoked.Add(oldPublicKey, KeyRevoked)
+		require.NoError(t, err)
+
+		_, err = revoked.Decode(legacy)
+		assert.ErrorIs(t, err, ErrVerifySignature)
+	})
+
+	t.Run("no keys", func(t *testing.T) {
+		var nilKeyring *Keyring
+
+		_, err := nilKeyring.Decode(newEncoded)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+
+		_, err = NewKeyring().Decode(newEncoded)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("unverified decode has no signer", func(t *testing.T) {
+		decoded, err := DecodeUnverified(newEncoded)
+		require.NoError(t, err)
+		assert.Empty(t, decoded.SignedBy())
+	})
+}
+
+// stripKeyID rewrites an encoded license into the format used before key IDs were introduced.
+func stripKeyID(t *testing.T, encoded []byte) []byte {
+	t.Helper()
+
+	block, _ := pem.Decode(encoded)
+	require.NotNil(t, block)
+
+	decompressed, err := decompress(block.Bytes)
+	require.NoError(t, err)
+
+	var content licenseContent
+	require.NoError(t, json.Unmarshal(decompressed, &content))
+
+	content.KeyID = ""
+
+	contentBytes, err := json.Marshal(content)
+	require.NoError(t, err)
+
+	compressed, err := compress(contentBytes)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:    block.Type,
+		Headers: block.Headers,
+		Bytes:   compressed,
+	})
+}
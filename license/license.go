@@ -4,37 +4,89 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"slices"
 	"time"
 )
 
 type License struct {
-	ID           string          `json:"id,omitempty"`  // License ID
-	Customer     string          `json:"cus,omitempty"` // Customer ID
-	Subscription string          `json:"sub,omitempty"` // Subscription ID
-	Type         string          `json:"typ,omitempty"` // License Type
-	IssuedAt     int64           `json:"iat,omitempty"` // Issued At
-	ExpiredAt    int64           `json:"exp,omitempty"` // Expires At
-	Data         json.RawMessage `json:"dat,omitempty"` // Metadata
+	ID           string           `json:"id,omitempty"`  // License ID
+	Customer     string           `json:"cus,omitempty"` // Customer ID
+	Subscription string           `json:"sub,omitempty"` // Subscription ID
+	Type         string           `json:"typ,omitempty"` // License Type
+	IssuedAt     int64            `json:"iat,omitempty"` // Issued At
+	NotBefore    int64            `json:"nbf,omitempty"` // Not Before
+	ExpiredAt    int64            `json:"exp,omitempty"` // Expires At
+	GracePeriod  int64            `json:"grc,omitempty"` // Grace Period after ExpiredAt, in seconds
+	Machine      *MachineBinding  `json:"mch,omitempty"` // Machine Binding
+	Features     []string         `json:"ftr,omitempty"` // Granted Features
+	Limits       map[string]int64 `json:"lim,omitempty"` // Named Limits
+	Data         json.RawMessage  `json:"dat,omitempty"` // Metadata
+
+	signers      []string
+	signed       []byte
+	certificates []*IssuerCertificate
 }
 
-// Expired returns true if the license is expired.
-func (lic *License) Expired() bool {
-	if lic.ExpiredAt > 0 && time.Now().UTC().Unix() >= lic.ExpiredAt {
-		return true
+// SignedBy returns the ID of the key that verified the license signature.
+// It is empty if the license was decoded without signature verification.
+func (lic *License) SignedBy() string {
+	if len(lic.signers) == 0 {
+		return ""
 	}
 
-	return false
+	return lic.signers[0]
+}
+
+// Signers returns the IDs of all keys that verified a signature of the license, starting with
+// the primary key. It is empty if the license was decoded without signature verification.
+func (lic *License) Signers() []string {
+	return slices.Clone(lic.signers)
+}
+
+// Certificates returns the verified issuer certificates of the license, starting with the one signed
+// by the root key. It is empty if the license was signed directly by a trusted key.
+func (lic *License) Certificates() []*IssuerCertificate {
+	return slices.Clone(lic.certificates)
+}
+
+// Expired returns true if the license is expired.
+func (lic *License) Expired() bool {
+	return lic.ExpiredAsOf(time.Now())
 }
 
+// GetFingerprint returns the base64url encoded SHA-256 hash of the canonical JSON (RFC 8785)
+// serialization of the license, with integral numbers written exactly. It does not depend on the
+// key order, whitespace or number notation of Data, so it is the same for the issued and the
+// decoded license and stays stable across library versions as long as the claims do not change.
+// Claims added in later versions are omitted when empty and do not change the fingerprint of
+// licenses that do not use them.
+//
+// Fingerprints of earlier releases hashed the json.Marshal output, so every fingerprint differs
+// from the one computed before canonical serialization; recompute stored fingerprints, such as
+// revocation entries and activation records, when upgrading.
 func (lic *License) GetFingerprint() (string, error) {
-	licData, err := json.Marshal(lic)
+	licData, err := canonicalJSON(lic)
 	if err != nil {
 		return "", err
 	}
 
-	hash := sha256.Sum256(licData)
+	return fingerprintOf(licData), nil
+}
+
+// SignedFingerprint returns the base64url encoded SHA-256 hash of the exact bytes covered by
+// the license signature. It identifies one issued license key: re-issuing the same claims
+// yields a different signed fingerprint only if the serialized bytes differ.
+// It is only available for a license returned by a decode function.
+func (lic *License) SignedFingerprint() (string, error) {
+	if lic.signed == nil {
+		return "", ErrNotDecoded
+	}
+
+	return fingerprintOf(lic.signed), nil
+}
 
-	hashBase64 := base64.RawURLEncoding.EncodeToString(hash[:])
+func fingerprintOf(data []byte) string {
+	hash := sha256.Sum256(data)
 
-	return hashBase64, nil
+	return base64.RawURLEncoding.EncodeToString(hash[:])
 }
@@ -1,7 +1,11 @@
 package license
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
+	"math"
 	"testing"
 	"time"
 
@@ -91,7 +95,7 @@ func TestLicense_GetFingerprint(t *testing.T) {
 				ExpiredAt:    1672531200,
 				Data:         []byte(`{"feature":"test"}`),
 			},
-			expectedHash: "vlw2Q4fXj_6lgcc61cYu0BUH1GEjaRarXuPh-v0O-jw",
+			expectedHash: "Ri0gSHOkF2rQTGizLe-6kNVKHs2FQIk8VuTguDWKDXM",
 			wantErr:      false,
 		},
 	}
@@ -119,3 +123,92 @@ func TestLicense_GetFingerprint(t *testing.T) {
 		})
 	}
 }
+
+func TestLicense_GetFingerprintCanonical(t *testing.T) {
+	compact := &License{ID: "test-id", Data: []byte(`{"a":1,"b":[true,null]}`)}
+	formatted := &License{ID: "test-id", Data: []byte("{\n  \"b\": [ true, null ],\n  \"a\": 1.0\n}")}
+
+	compactHash, err := compact.GetFingerprint()
+	require.NoError(t, err)
+
+	formattedHash, err := formatted.GetFingerprint()
+	require.NoError(t, err)
+
+	assert.Equal(t, compactHash, formattedHash)
+
+	t.Run("stable across encoding", func(t *testing.T) {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		encoded, err := formatted.Encode(privateKey)
+		require.NoError(t, err)
+
+		decoded, err := Decode(encoded, publicKey)
+		require.NoError(t, err)
+
+		decodedHash, err := decoded.GetFingerprint()
+		require.NoError(t, err)
+		assert.Equal(t, compactHash, decodedHash)
+	})
+
+	t.Run("large integer claims", func(t *testing.T) {
+		tests := []struct {
+			name string
+			a, b *License
+		}{
+			{
+				name: "limits",
+				a:    &License{ID: "test-id", Limits: map[string]int64{"x": 9007199254740993}},
+				b:    &License{ID: "test-id", Limits: map[string]int64{"x": 9007199254740992}},
+			},
+			{
+				name: "expire time",
+				a:    &License{ID: "test-id", ExpiredAt: math.MaxInt64},
+				b:    &License{ID: "test-id", ExpiredAt: math.MaxInt64 - 1},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				aHash, err := tt.a.GetFingerprint()
+				require.NoError(t, err)
+
+				bHash, err := tt.b.GetFingerprint()
+				require.NoError(t, err)
+
+				assert.NotEqual(t, aHash, bHash)
+			})
+		}
+	})
+}
+
+func TestLicense_SignedFingerprint(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{ID: "test-id", Data: []byte(`{"a":1}`)}
+
+	_, err = license.SignedFingerprint()
+	assert.ErrorIs(t, err, ErrNotDecoded)
+
+	encoded, err := license.Encode(privateKey)
+	require.NoError(t, err)
+
+	decoded, err := Decode(encoded, publicKey)
+	require.NoError(t, err)
+
+	signed, err := decoded.SignedFingerprint()
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(license)
+	require.NoError(t, err)
+	assert.Equal(t, fingerprintOf(payload), signed)
+
+	t.Run("independent of later changes", func(t *testing.T) {
+		decoded.Data = []byte(`{ "a": 1 }`)
+
+		again, err := decoded.SignedFingerprint()
+		require.NoError(t, err)
+		assert.Equal(t, signed, again)
+	})
+}
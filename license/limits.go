@@ -0,0 +1,89 @@
+package license
+
+import (
+	"io"
+	"os"
+)
+
+// DefaultDecodeOptions are the limits applied when decoding without explicit options.
+var DefaultDecodeOptions = DecodeOptions{
+	MaxInputBytes:        1 << 20,
+	MaxDecompressedBytes: 4 << 20,
+	MaxDataBytes:         1 << 20,
+	MaxHeaders:           16,
+}
+
+// DecodeOptions bounds the resources used to decode a license and sets how many signatures it needs.
+// A zero limit uses the limit from DefaultDecodeOptions, a negative limit disables it.
+type DecodeOptions struct {
+	// MaxInputBytes limits the size of the PEM encoded license.
+	MaxInputBytes int64
+	// MaxDecompressedBytes limits the size of the decompressed license content.
+	MaxDecompressedBytes int64
+	// MaxDataBytes limits the size of the license Data.
+	MaxDataBytes int64
+	// MaxHeaders limits the number of PEM headers.
+	MaxHeaders int
+
+	// Threshold is the number of distinct trusted keys that must have signed a license.
+	// Zero or one requires a single signature.
+	Threshold int
+}
+
+// withDefaults resolves zero values to the defaults and negative values to no limit.
+func (opts DecodeOptions) withDefaults() DecodeOptions {
+	return DecodeOptions{
+		MaxInputBytes:        resolveLimit(opts.MaxInputBytes, DefaultDecodeOptions.MaxInputBytes),
+		MaxDecompressedBytes: resolveLimit(opts.MaxDecompressedBytes, DefaultDecodeOptions.MaxDecompressedBytes),
+		MaxDataBytes:         resolveLimit(opts.MaxDataBytes, DefaultDecodeOptions.MaxDataBytes),
+		MaxHeaders:           int(resolveLimit(int64(opts.MaxHeaders), int64(DefaultDecodeOptions.MaxHeaders))),
+		Threshold:            opts.Threshold,
+	}
+}
+
+// threshold returns the number of signatures required.
+func (opts DecodeOptions) threshold() int {
+	return max(opts.Threshold, 1)
+}
+
+func resolveLimit(value, defaultValue int64) int64 {
+	switch {
+	case value == 0:
+		return defaultValue
+	case value < 0:
+		return -1
+	default:
+		return value
+	}
+}
+
+// exceeds reports whether size is over the resolved limit.
+func exceeds(size, limit int64) bool {
+	return limit >= 0 && size > limit
+}
+
+// readFileLimit reads the file and fails with a LimitError if it is larger than limit.
+func readFileLimit(path string, limit int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var reader io.Reader = file
+	if limit >= 0 {
+		reader = io.LimitReader(file, limit+1)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if exceeds(int64(len(data)), limit) {
+		return nil, newError(CodeTooLarge, LayerPEM, &LimitError{Limit: "input", Max: limit})
+	}
+
+	return data, nil
+}
@@ -0,0 +1,204 @@
+package license
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeOptions_WithDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     DecodeOptions
+		expected DecodeOptions
+	}{
+		{
+			name:     "zero value uses defaults",
+			opts:     DecodeOptions{},
+			expected: DefaultDecodeOptions,
+		},
+		{
+			name: "negative values disable limits",
+			opts: DecodeOptions{
+				MaxInputBytes:        -1,
+				MaxDecompressedBytes: -5,
+				MaxDataBytes:         -1,
+				MaxHeaders:           -1,
+			},
+			expected: DecodeOptions{
+				MaxInputBytes:        -1,
+				MaxDecompressedBytes: -1,
+				MaxDataBytes:         -1,
+				MaxHeaders:           -1,
+			},
+		},
+		{
+			name: "explicit values are kept",
+			opts: DecodeOptions{
+				MaxInputBytes:        10,
+				MaxDecompressedBytes: 20,
+				MaxDataBytes:         30,
+				MaxHeaders:           2,
+			},
+			expected: DecodeOptions{
+				MaxInputBytes:        10,
+				MaxDecompressedBytes: 20,
+				MaxDataBytes:         30,
+				MaxHeaders:           2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.opts.withDefaults())
+		})
+	}
+}
+
+func TestDecodeWithOptions_Limits(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{
+		ID:   "limits-license",
+		Data: []byte(`{"payload":"` + string(bytes.Repeat([]byte("a"), 1024)) + `"}`),
+	}
+
+	encoded, err := license.Encode(privateKey)
+	require.NoError(t, err)
+
+	t.Run("within limits", func(t *testing.T) {
+		decoded, err := DecodeWithOptions(encoded, DecodeOptions{}, publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, license.Data, decoded.Data)
+	})
+
+	tests := []struct {
+		name  string
+		data  []byte
+		opts  DecodeOptions
+		limit string
+	}{
+		{
+			name:  "input too large",
+			data:  encoded,
+			opts:  DecodeOptions{MaxInputBytes: 64},
+			limit: "input",
+		},
+		{
+			name:  "decompressed content too large",
+			data:  encoded,
+			opts:  DecodeOptions{MaxDecompressedBytes: 64},
+			limit: "decompressed",
+		},
+		{
+			name:  "data too large",
+			data:  encoded,
+			opts:  DecodeOptions{MaxDataBytes: 64},
+			limit: "data",
+		},
+		{
+			name: "too many headers",
+			data: func() []byte {
+				block, _ := pem.Decode(encoded)
+				for i := range 4 {
+					block.Headers[fmt.Sprintf("x-%d", i)] = "value"
+				}
+				return pem.EncodeToMemory(block)
+			}(),
+			opts:  DecodeOptions{MaxHeaders: 2},
+			limit: "headers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeWithOptions(tt.data, tt.opts, publicKey)
+			assert.ErrorIs(t, err, ErrLimitExceeded)
+
+			var limitErr *LimitError
+			require.True(t, errors.As(err, &limitErr))
+			assert.Equal(t, tt.limit, limitErr.Limit)
+		})
+	}
+
+	t.Run("disabled limit", func(t *testing.T) {
+		_, err := DecodeWithOptions(encoded, DecodeOptions{MaxDataBytes: -1}, publicKey)
+		require.NoError(t, err)
+	})
+}
+
+func TestDecode_DecompressionBomb(t *testing.T) {
+	compressed, err := compress(make([]byte, 16<<20))
+	require.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{
+		Type:  "LICENSE KEY",
+		Bytes: compressed,
+	})
+	require.Less(t, len(data), int(DefaultDecodeOptions.MaxInputBytes))
+
+	_, err = Decode(data)
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+}
+
+func TestDecodeFileWithOptions(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{
+		ID: "limits-file-license",
+	}
+
+	encoded, err := license.Encode(privateKey)
+	require.NoError(t, err)
+
+	tempFile, err := os.CreateTemp("", "limits_license_*.lic")
+	require.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(encoded)
+	require.NoError(t, err)
+	tempFile.Close()
+
+	t.Run("within limits", func(t *testing.T) {
+		decoded, err := DecodeFileWithOptions(tempFile.Name(), DecodeOptions{}, publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+	})
+
+	t.Run("file too large", func(t *testing.T) {
+		_, err := DecodeFileWithOptions(tempFile.Name(), DecodeOptions{MaxInputBytes: 16}, publicKey)
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+	})
+
+	t.Run("verifier with decode options", func(t *testing.T) {
+		verifier, err := NewVerifier(publicKey)
+		require.NoError(t, err)
+
+		_, err = verifier.WithDecodeOptions(DecodeOptions{MaxInputBytes: 16}).VerifyFile(tempFile.Name())
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+
+		_, err = verifier.WithDecodeOptions(DecodeOptions{MaxInputBytes: 16}).Verify(encoded)
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+
+		decoded, err := verifier.VerifyFile(tempFile.Name())
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+	})
+}
+
+func TestLimitError(t *testing.T) {
+	err := &LimitError{Limit: "data", Max: 10}
+	assert.Equal(t, "license data exceeds the limit of 10", err.Error())
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+}
@@ -0,0 +1,40 @@
+package license
+
+// MachineBinding binds a node-locked license to the hashed components of a machine,
+// as collected by the fingerprint package.
+type MachineBinding struct {
+	Components []string `json:"cmp"`           // Component Hashes
+	Threshold  int      `json:"thr,omitempty"` // Minimum matching components; all if zero
+}
+
+// threshold returns the number of components that must match.
+func (mb *MachineBinding) threshold() int {
+	if mb.Threshold > 0 {
+		return mb.Threshold
+	}
+
+	return len(mb.Components)
+}
+
+// valid reports whether the binding can be satisfied.
+func (mb *MachineBinding) valid() bool {
+	return len(mb.Components) > 0 && mb.Threshold >= 0 && mb.Threshold <= len(mb.Components)
+}
+
+// Matches reports whether at least the threshold of bound components is present in the host components.
+func (mb *MachineBinding) Matches(hostComponents []string) bool {
+	host := make(map[string]struct{}, len(hostComponents))
+	for _, component := range hostComponents {
+		host[component] = struct{}{}
+	}
+
+	var matched int
+
+	for _, component := range mb.Components {
+		if _, ok := host[component]; ok {
+			matched++
+		}
+	}
+
+	return matched >= mb.threshold()
+}
@@ -0,0 +1,100 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMachineBinding_Matches(t *testing.T) {
+	binding := &MachineBinding{
+		Components: []string{"a", "b", "c", "d", "e"},
+		Threshold:  3,
+	}
+
+	tests := []struct {
+		name     string
+		binding  *MachineBinding
+		host     []string
+		expected bool
+	}{
+		{
+			name:     "all components",
+			binding:  binding,
+			host:     []string{"a", "b", "c", "d", "e"},
+			expected: true,
+		},
+		{
+			name:     "threshold reached",
+			binding:  binding,
+			host:     []string{"a", "c", "e", "x"},
+			expected: true,
+		},
+		{
+			name:     "threshold missed",
+			binding:  binding,
+			host:     []string{"a", "b", "x", "y"},
+			expected: false,
+		},
+		{
+			name:     "no host components",
+			binding:  binding,
+			host:     nil,
+			expected: false,
+		},
+		{
+			name:     "zero threshold requires all",
+			binding:  &MachineBinding{Components: []string{"a", "b"}},
+			host:     []string{"a"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.binding.Matches(tt.host))
+		})
+	}
+}
+
+func TestLicense_Machine(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("roundtrip and validate", func(t *testing.T) {
+		license := &License{
+			ID: "machine-license",
+			Machine: &MachineBinding{
+				Components: []string{"a", "b", "c"},
+				Threshold:  2,
+			},
+		}
+
+		encoded, err := license.Encode(privateKey)
+		require.NoError(t, err)
+
+		decoded, err := Decode(encoded, publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, license.Machine, decoded.Machine)
+
+		assert.NoError(t, decoded.Validate(ValidateOptions{MachineComponents: []string{"a", "c"}}))
+		assert.ErrorIs(t, decoded.Validate(ValidateOptions{MachineComponents: []string{"a"}}), ErrMachineMismatch)
+		assert.ErrorIs(t, decoded.Validate(ValidateOptions{}), ErrMachineMismatch)
+	})
+
+	t.Run("invalid bindings", func(t *testing.T) {
+		for _, binding := range []*MachineBinding{
+			{},
+			{Components: []string{"a"}, Threshold: 2},
+			{Components: []string{"a"}, Threshold: -1},
+		} {
+			license := &License{ID: "machine-license", Machine: binding}
+
+			_, err := license.Encode(privateKey)
+			assert.ErrorIs(t, err, ErrMachineBinding)
+		}
+	})
+}
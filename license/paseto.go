@@ -0,0 +1,243 @@
+package license
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const pasetoHeader = "v4.public."
+
+// pasetoTimeClaims are the claims that PASETO encodes as RFC 3339 strings and License as Unix seconds.
+var pasetoTimeClaims = []string{"iat", "nbf", "exp"}
+
+// pasetoFooter is the footer of a license PASETO.
+type pasetoFooter struct {
+	KeyID string `json:"kid,omitempty"`
+}
+
+// EncodePASETO signs the license with the ed25519 private key and returns it as a PASETO v4.public token.
+// The claims are those of License, with the license ID as the "jti" claim and the times as RFC 3339
+// strings. The footer carries the ID of the signing key. The implicit assertion, such as the product
+// name, is signed but not included in the token and must be passed again to DecodePASETO.
+func (lic *License) EncodePASETO(privateKey ed25519.PrivateKey, implicit string) (string, error) {
+	if err := lic.validateClaims(); err != nil {
+		return "", err
+	}
+
+	if privateKey == nil {
+		return "", ErrPrivateKeyNotDefined
+	}
+
+	claims, err := lic.pasetoClaims()
+	if err != nil {
+		return "", err
+	}
+
+	footer, err := json.Marshal(pasetoFooter{
+		KeyID: KeyID(privateKey.Public().(ed25519.PublicKey)),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signature := ed25519.Sign(privateKey, pae([]byte(pasetoHeader), claims, footer, []byte(implicit)))
+
+	return pasetoHeader +
+		base64.RawURLEncoding.EncodeToString(append(claims, signature...)) + "." +
+		base64.RawURLEncoding.EncodeToString(footer), nil
+}
+
+// DecodePASETO decodes a PASETO v4.public license token and verifies the signature over the token
+// and the implicit assertion using the ed25519 public keys. At least one public key is required.
+func DecodePASETO(token, implicit string, publicKeys ...ed25519.PublicKey) (*License, error) {
+	keyring, err := newDecodeKeyring(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyring == nil || keyring.Len() == 0 {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	dec := decoder{keyring: keyring}
+
+	return dec.decodePASETO(token, implicit)
+}
+
+// VerifyPASETO decodes the PASETO v4.public license token and verifies the signature.
+func (v *Verifier) VerifyPASETO(token, implicit string) (*License, error) {
+	if !v.ready() {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	lic, err := v.dec.decodePASETO(token, implicit)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.checkRevoked(lic)
+}
+
+func (d *decoder) decodePASETO(token, implicit string) (*License, error) {
+	limits := d.opts.withDefaults()
+
+	if exceeds(int64(len(token)), limits.MaxInputBytes) {
+		return nil, newError(CodeTooLarge, LayerPEM, &LimitError{Limit: "input", Max: limits.MaxInputBytes})
+	}
+
+	token = strings.TrimSpace(token)
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 && len(parts) != 4 {
+		return nil, newError(CodeMalformed, LayerPEM, ErrMalformedLicense)
+	}
+
+	if header := parts[0] + "." + parts[1] + "."; header != pasetoHeader {
+		return nil, newError(CodeUnsupportedFormat, LayerPEM, fmt.Errorf("%w: %q", ErrUnsupportedVersion, header))
+	}
+
+	parts = parts[2:]
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, malformedError(CodeCorrupted, LayerEnvelope, err)
+	}
+
+	if len(body) < ed25519.SignatureSize {
+		return nil, newError(CodeMalformed, LayerEnvelope, ErrMalformedLicense)
+	}
+
+	var footer []byte
+
+	if len(parts) == 2 {
+		if footer, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+			return nil, malformedError(CodeCorrupted, LayerEnvelope, err)
+		}
+	}
+
+	var footerContent pasetoFooter
+
+	if len(footer) > 0 {
+		if err := json.Unmarshal(footer, &footerContent); err != nil {
+			return nil, malformedError(CodeCorrupted, LayerEnvelope, err)
+		}
+	}
+
+	claims := body[:len(body)-ed25519.SignatureSize]
+	signature := body[len(body)-ed25519.SignatureSize:]
+
+	message := pae([]byte(pasetoHeader), claims, footer, []byte(implicit))
+
+	keyID, err := d.keyring.verify(AlgorithmEd25519, footerContent.KeyID, message, signature)
+	if err != nil {
+		return nil, signatureError(err)
+	}
+
+	payload, err := licenseClaimsFromPASETO(claims)
+	if err != nil {
+		return nil, malformedError(CodeClaimsInvalid, LayerClaims, err)
+	}
+
+	license, err := d.decodeClaims(payload, []string{keyID})
+	if err != nil {
+		return nil, err
+	}
+
+	license.signed = message
+
+	return license, nil
+}
+
+// pasetoClaims returns the license claims with the license ID as the "jti" claim and the times as RFC 3339 strings.
+func (lic *License) pasetoClaims() ([]byte, error) {
+	payload, err := lic.jwtClaims()
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, err
+	}
+
+	for _, name := range pasetoTimeClaims {
+		value, ok := claims[name]
+		if !ok {
+			continue
+		}
+
+		var unix int64
+		if err := json.Unmarshal(value, &unix); err != nil {
+			return nil, err
+		}
+
+		if claims[name], err = json.Marshal(time.Unix(unix, 0).UTC().Format(time.RFC3339)); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(claims)
+}
+
+// licenseClaimsFromPASETO returns the license claims of PASETO claims, with the "jti" claim as the
+// license ID and the times as Unix seconds.
+func licenseClaimsFromPASETO(data []byte) ([]byte, error) {
+	var claims map[string]json.RawMessage
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, err
+	}
+
+	for _, name := range pasetoTimeClaims {
+		value, ok := claims[name]
+		if !ok {
+			continue
+		}
+
+		var text string
+		if err := json.Unmarshal(value, &text); err != nil {
+			return nil, fmt.Errorf("%s claim: %w", name, err)
+		}
+
+		t, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return nil, fmt.Errorf("%s claim: %w", name, err)
+		}
+
+		if claims[name], err = json.Marshal(t.Unix()); err != nil {
+			return nil, err
+		}
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return licenseClaimsFromJWT(payload)
+}
+
+// pae returns the pre-authentication encoding of the pieces, as defined by PASETO.
+func pae(pieces ...[]byte) []byte {
+	var buf bytes.Buffer
+
+	writeLength := func(n int) {
+		var length [8]byte
+		binary.LittleEndian.PutUint64(length[:], uint64(n)&^(1<<63))
+		buf.Write(length[:])
+	}
+
+	writeLength(len(pieces))
+
+	for _, piece := range pieces {
+		writeLength(len(piece))
+		buf.Write(piece)
+	}
+
+	return buf.Bytes()
+}
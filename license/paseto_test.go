@@ -0,0 +1,207 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPAE(t *testing.T) {
+	tests := []struct {
+		name     string
+		pieces   [][]byte
+		expected string
+	}{
+		{
+			name:     "no pieces",
+			pieces:   nil,
+			expected: "0000000000000000",
+		},
+		{
+			name:     "empty piece",
+			pieces:   [][]byte{{}},
+			expected: "01000000000000000000000000000000",
+		},
+		{
+			name:     "one piece",
+			pieces:   [][]byte{[]byte("test")},
+			expected: "0100000000000000040000000000000074657374",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, hex.EncodeToString(pae(tt.pieces...)))
+		})
+	}
+}
+
+func TestDecodePASETO_TestVector(t *testing.T) {
+	// Test vector 4-S-1 of the PASETO specification.
+	publicKey, err := hex.DecodeString("1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+
+	token := "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9" +
+		"bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA"
+
+	// The signature verifies, but the vector has no "jti" claim, so it is not a license.
+	_, err = DecodePASETO(token, "", publicKey)
+	assert.ErrorIs(t, err, ErrLicenseIDNotDefined)
+
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, err = DecodePASETO(token, "", otherPublicKey)
+	assert.ErrorIs(t, err, ErrVerifySignature)
+
+	body, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[2])
+	require.NoError(t, err)
+
+	payload, err := licenseClaimsFromPASETO(body[:len(body)-ed25519.SignatureSize])
+	require.NoError(t, err)
+
+	var license License
+	require.NoError(t, json.Unmarshal(payload, &license))
+	assert.Equal(t, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC).Unix(), license.ExpiredAt)
+}
+
+func TestEncodePASETO_DecodePASETO(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	const product = "acme-appliance"
+
+	license := &License{
+		ID:        "paseto-license",
+		Customer:  "customer-123",
+		IssuedAt:  1700000000,
+		ExpiredAt: 1800000000,
+		Limits:    map[string]int64{"core.users.max": 10},
+	}
+
+	token, err := license.EncodePASETO(privateKey, product)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, "v4.public."))
+
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 4)
+
+	t.Run("claims and footer", func(t *testing.T) {
+		body, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"jti": "paseto-license",
+			"cus": "customer-123",
+			"iat": "2023-11-14T22:13:20Z",
+			"exp": "2027-01-15T08:00:00Z",
+			"lim": {"core.users.max": 10}
+		}`, string(body[:len(body)-ed25519.SignatureSize]))
+
+		footer, err := base64.RawURLEncoding.DecodeString(parts[3])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"kid":"`+KeyID(publicKey)+`"}`, string(footer))
+	})
+
+	t.Run("roundtrip", func(t *testing.T) {
+		decoded, err := DecodePASETO(token, product, publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+		assert.Equal(t, license.IssuedAt, decoded.IssuedAt)
+		assert.Equal(t, license.ExpiredAt, decoded.ExpiredAt)
+		assert.Equal(t, license.Limits, decoded.Limits)
+		assert.Equal(t, KeyID(publicKey), decoded.SignedBy())
+	})
+
+	t.Run("verifier", func(t *testing.T) {
+		verifier, err := NewVerifier(publicKey)
+		require.NoError(t, err)
+
+		decoded, err := verifier.VerifyPASETO(token, product)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+
+		_, err = (&Verifier{}).VerifyPASETO(token, product)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("keys required", func(t *testing.T) {
+		_, err := DecodePASETO(token, product)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		token       string
+		implicit    string
+		key         ed25519.PublicKey
+		expectedErr error
+	}{
+		{
+			name:        "other product",
+			token:       token,
+			implicit:    "other-product",
+			key:         publicKey,
+			expectedErr: ErrVerifySignature,
+		},
+		{
+			name:        "other key",
+			token:       token,
+			implicit:    product,
+			key:         otherPublicKey,
+			expectedErr: ErrUnknownKey,
+		},
+		{
+			name:        "modified footer",
+			token:       strings.Join(parts[:3], ".") + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"kid":"`+KeyID(publicKey)+`","x":1}`)),
+			implicit:    product,
+			key:         publicKey,
+			expectedErr: ErrVerifySignature,
+		},
+		{
+			name:        "local purpose",
+			token:       "v4.local." + parts[2],
+			implicit:    product,
+			key:         publicKey,
+			expectedErr: ErrUnsupportedVersion,
+		},
+		{
+			name:        "older version",
+			token:       "v2.public." + parts[2],
+			implicit:    product,
+			key:         publicKey,
+			expectedErr: ErrUnsupportedVersion,
+		},
+		{
+			name:        "not a token",
+			token:       "license",
+			implicit:    product,
+			key:         publicKey,
+			expectedErr: ErrMalformedLicense,
+		},
+		{
+			name:        "truncated",
+			token:       "v4.public.AAAA",
+			implicit:    product,
+			key:         publicKey,
+			expectedErr: ErrMalformedLicense,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodePASETO(tt.token, tt.implicit, tt.key)
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
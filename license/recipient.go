@@ -0,0 +1,215 @@
+package license
+
+import (
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const recipientKeyInfo = "go-license x25519 recipient v1"
+
+type recipientContent struct {
+	KeyID     string `json:"k"`
+	Ephemeral string `json:"e"`
+	Key       string `json:"w"`
+}
+
+// RecipientKeyID returns the identifier of the X25519 recipient public key carried in encoded licenses.
+func RecipientKeyID(publicKey *ecdh.PublicKey) string {
+	return keyIDFromBytes(publicKey.Bytes())
+}
+
+// sealRecipientContent returns the content with the payload, its checksum and its signatures encrypted
+// to the recipients. Only the key ID, the issuer certificates and the recipient entries stay readable,
+// so the encoded license has nothing derived from the claims to test guesses of them against.
+func sealRecipientContent(content licenseContent, payload []byte, recipients []*ecdh.PublicKey) (licenseContent, error) {
+	inner, err := json.Marshal(licenseContent{
+		Data:       base64.RawURLEncoding.EncodeToString(payload),
+		Sign:       content.Sign,
+		DataHash:   content.DataHash,
+		Signatures: content.Signatures,
+	})
+	if err != nil {
+		return licenseContent{}, err
+	}
+
+	encryptedData, nonce, entries, err := sealRecipients(inner, recipients)
+	if err != nil {
+		return licenseContent{}, err
+	}
+
+	return licenseContent{
+		Data:         base64.RawURLEncoding.EncodeToString(encryptedData),
+		KeyID:        content.KeyID,
+		Nonce:        nonce,
+		Recipients:   entries,
+		Certificates: content.Certificates,
+	}, nil
+}
+
+// openRecipientContent decrypts content sealed with sealRecipientContent using the recipient keys
+// of the decoder and returns it with the payload, its checksum and its signatures.
+func (d *decoder) openRecipientContent(content licenseContent) (licenseContent, error) {
+	if content.Sign != "" || content.DataHash != "" || len(content.Signatures) > 0 {
+		return licenseContent{}, newError(CodeMalformed, LayerEnvelope, ErrMalformedLicense)
+	}
+
+	encryptedData, err := base64.RawURLEncoding.DecodeString(content.Data)
+	if err != nil {
+		return licenseContent{}, malformedError(CodeCorrupted, LayerEnvelope, err)
+	}
+
+	inner, err := openRecipients(encryptedData, content.Nonce, content.Recipients, d.recipientKeys)
+	if errors.Is(err, ErrRecipientKeyNotFound) {
+		return licenseContent{}, newError(CodeRecipientKeyNotFound, LayerDecryption, err)
+	} else if err != nil {
+		return licenseContent{}, newError(CodeDecryptionFailed, LayerDecryption, err)
+	}
+
+	var sealed licenseContent
+	if err := json.Unmarshal(inner, &sealed); err != nil {
+		return licenseContent{}, malformedError(CodeCorrupted, LayerDecryption, err)
+	}
+
+	content.Data = sealed.Data
+	content.Sign = sealed.Sign
+	content.DataHash = sealed.DataHash
+	content.Signatures = sealed.Signatures
+
+	return content, nil
+}
+
+// sealRecipients encrypts the data with a random content key and wraps the content key
+// for every recipient using an ephemeral X25519 key agreement.
+func sealRecipients(data []byte, recipients []*ecdh.PublicKey) ([]byte, string, []recipientContent, error) {
+	contentKey := make([]byte, chacha20poly1305.KeySize)
+	if _, err := rand.Read(contentKey); err != nil {
+		return nil, "", nil, err
+	}
+
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, "", nil, err
+	}
+
+	encryptedData, err := encryptData(data, contentKey, nonce)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	entries := make([]recipientContent, 0, len(recipients))
+
+	for _, recipient := range recipients {
+		if recipient == nil || recipient.Curve() != ecdh.X25519() {
+			return nil, "", nil, ErrInvalidRecipientKey
+		}
+
+		ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		shared, err := ephemeral.ECDH(recipient)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		wrapKey, err := recipientWrapKey(shared, ephemeral.PublicKey().Bytes(), recipient.Bytes())
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		wrappedKey, err := encryptData(contentKey, wrapKey, make([]byte, chacha20poly1305.NonceSize))
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		entries = append(entries, recipientContent{
+			KeyID:     RecipientKeyID(recipient),
+			Ephemeral: base64.RawURLEncoding.EncodeToString(ephemeral.PublicKey().Bytes()),
+			Key:       base64.RawURLEncoding.EncodeToString(wrappedKey),
+		})
+	}
+
+	return encryptedData, base64.RawURLEncoding.EncodeToString(nonce), entries, nil
+}
+
+// openRecipients unwraps the content key with the first matching recipient private key and decrypts the data.
+func openRecipients(data []byte, encodedNonce string, entries []recipientContent, keys []*ecdh.PrivateKey) ([]byte, error) {
+	if len(keys) == 0 {
+		return nil, ErrRecipientKeyNotFound
+	}
+
+	nonce, err := base64.RawURLEncoding.DecodeString(encodedNonce)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if key == nil || key.Curve() != ecdh.X25519() {
+			continue
+		}
+
+		keyID := RecipientKeyID(key.PublicKey())
+
+		for _, entry := range entries {
+			if entry.KeyID != keyID {
+				continue
+			}
+
+			contentKey, err := unwrapRecipientKey(entry, key)
+			if err != nil {
+				return nil, err
+			}
+
+			return decryptData(data, contentKey, nonce)
+		}
+	}
+
+	return nil, ErrRecipientKeyNotFound
+}
+
+func unwrapRecipientKey(entry recipientContent, key *ecdh.PrivateKey) ([]byte, error) {
+	ephemeralBytes, err := base64.RawURLEncoding.DecodeString(entry.Ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := base64.RawURLEncoding.DecodeString(entry.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeral, err := ecdh.X25519().NewPublicKey(ephemeralBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := key.ECDH(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapKey, err := recipientWrapKey(shared, ephemeralBytes, key.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return decryptData(wrappedKey, wrapKey, make([]byte, chacha20poly1305.NonceSize))
+}
+
+// recipientWrapKey derives the key wrapping the content key from the X25519 shared secret,
+// bound to both the ephemeral and the recipient public keys.
+func recipientWrapKey(shared, ephemeral, recipient []byte) ([]byte, error) {
+	salt := make([]byte, 0, len(ephemeral)+len(recipient))
+	salt = append(salt, ephemeral...)
+	salt = append(salt, recipient...)
+
+	return hkdf.Key(sha256.New, shared, salt, recipientKeyInfo, chacha20poly1305.KeySize)
+}
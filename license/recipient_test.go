@@ -0,0 +1,193 @@
+package license
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLicense_EncodeWithOptions_Recipients(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	recipientKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	otherRecipientKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{
+		ID:        "recipient-license",
+		IssuedAt:  time.Now().Unix(),
+		ExpiredAt: time.Now().Add(time.Hour).Unix(),
+		Data:      []byte(`{"price":1000}`),
+	}
+
+	encoded, err := license.EncodeWithOptions(privateKey, EncodeOptions{
+		Recipients: []*ecdh.PublicKey{recipientKey.PublicKey(), otherRecipientKey.PublicKey()},
+	})
+	require.NoError(t, err)
+
+	t.Run("each recipient can decode", func(t *testing.T) {
+		for _, key := range []*ecdh.PrivateKey{recipientKey, otherRecipientKey} {
+			decoded, err := DecodeWithRecipient(encoded, key, publicKey)
+			require.NoError(t, err)
+			assert.Equal(t, license.ID, decoded.ID)
+			assert.Equal(t, license.Data, decoded.Data)
+			assert.Equal(t, KeyID(publicKey), decoded.SignedBy())
+		}
+	})
+
+	t.Run("without recipient key", func(t *testing.T) {
+		_, err := Decode(encoded, publicKey)
+		assert.ErrorIs(t, err, ErrRecipientKeyNotFound)
+
+		_, err = DecodeUnverified(encoded)
+		assert.ErrorIs(t, err, ErrRecipientKeyNotFound)
+
+		_, err = DecodeWithRecipient(encoded, nil, publicKey)
+		assert.ErrorIs(t, err, ErrRecipientKeyNotFound)
+	})
+
+	t.Run("with wrong recipient key", func(t *testing.T) {
+		wrongKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		_, err = DecodeWithRecipient(encoded, wrongKey, publicKey)
+		assert.ErrorIs(t, err, ErrRecipientKeyNotFound)
+	})
+
+	t.Run("without public keys", func(t *testing.T) {
+		_, err := DecodeWithRecipient(encoded, recipientKey)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+
+		_, err = DecodeWithRecipient(encoded, recipientKey, nil)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("with wrong public key", func(t *testing.T) {
+		wrongPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		_, err = DecodeWithRecipient(encoded, recipientKey, wrongPublicKey)
+		assert.ErrorIs(t, err, ErrVerifySignature)
+	})
+
+	t.Run("verifier with recipient keys", func(t *testing.T) {
+		verifier, err := NewVerifier(publicKey)
+		require.NoError(t, err)
+
+		_, err = verifier.Verify(encoded)
+		assert.ErrorIs(t, err, ErrRecipientKeyNotFound)
+
+		decoded, err := verifier.WithRecipientKeys(recipientKey).Verify(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, license.Data, decoded.Data)
+	})
+
+	t.Run("no fields derived from the claims", func(t *testing.T) {
+		coSignerPublicKey, coSigner, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		cosigned, err := license.EncodeWithOptions(privateKey, EncodeOptions{
+			Recipients: []*ecdh.PublicKey{recipientKey.PublicKey()},
+			CoSigners:  []crypto.Signer{coSigner},
+		})
+		require.NoError(t, err)
+
+		block, _ := pem.Decode(cosigned)
+		require.NotNil(t, block)
+
+		decompressed, err := decompress(block.Bytes)
+		require.NoError(t, err)
+
+		var content map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(decompressed, &content))
+
+		fields := make([]string, 0, len(content))
+		for field := range content {
+			fields = append(fields, field)
+		}
+
+		assert.ElementsMatch(t, []string{"d", "k", "n", "r"}, fields)
+
+		keyring := NewKeyring()
+		_, err = keyring.Add(publicKey, KeyActive)
+		require.NoError(t, err)
+		_, err = keyring.Add(coSignerPublicKey, KeyActive)
+		require.NoError(t, err)
+
+		verifier, err := NewKeyringVerifier(keyring)
+		require.NoError(t, err)
+
+		decoded, err := verifier.WithThreshold(2).WithRecipientKeys(recipientKey).Verify(cosigned)
+		require.NoError(t, err)
+		assert.Equal(t, license.Data, decoded.Data)
+		assert.Len(t, decoded.Signers(), 2)
+	})
+
+	t.Run("signature outside the ciphertext", func(t *testing.T) {
+		block, _ := pem.Decode(encoded)
+		require.NotNil(t, block)
+
+		decompressed, err := decompress(block.Bytes)
+		require.NoError(t, err)
+
+		var content licenseContent
+		require.NoError(t, json.Unmarshal(decompressed, &content))
+
+		content.Sign = base64.RawURLEncoding.EncodeToString(make([]byte, ed25519.SignatureSize))
+
+		data, err := json.Marshal(content)
+		require.NoError(t, err)
+
+		block.Bytes, err = compress(data)
+		require.NoError(t, err)
+
+		_, err = DecodeWithRecipient(pem.EncodeToMemory(block), recipientKey, publicKey)
+		assert.ErrorIs(t, err, ErrMalformedLicense)
+	})
+}
+
+func TestLicense_EncodeWithOptions_InvalidRecipient(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	p256Key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{
+		ID: "invalid-recipient-license",
+	}
+
+	_, err = license.EncodeWithOptions(privateKey, EncodeOptions{
+		Recipients: []*ecdh.PublicKey{p256Key.PublicKey()},
+	})
+	assert.ErrorIs(t, err, ErrInvalidRecipientKey)
+
+	_, err = license.EncodeWithOptions(privateKey, EncodeOptions{
+		Recipients: []*ecdh.PublicKey{nil},
+	})
+	assert.ErrorIs(t, err, ErrInvalidRecipientKey)
+}
+
+func TestRecipientKeyID(t *testing.T) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keyID := RecipientKeyID(key.PublicKey())
+	assert.Equal(t, keyIDFromBytes(key.PublicKey().Bytes()), keyID)
+
+	otherKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	assert.NotEqual(t, keyID, RecipientKeyID(otherKey.PublicKey()))
+}
@@ -0,0 +1,144 @@
+package license
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"encoding/json"
+	"time"
+)
+
+const revocationListBlockType = "LICENSE REVOCATION LIST"
+
+// RevocationReason describes why a license was revoked.
+type RevocationReason string
+
+// Revocation reasons.
+const (
+	ReasonUnspecified   RevocationReason = "unspecified"
+	ReasonKeyCompromise RevocationReason = "key_compromise"
+	ReasonSuperseded    RevocationReason = "superseded"
+	ReasonCancelled     RevocationReason = "cancelled"
+	ReasonFraud         RevocationReason = "fraud"
+)
+
+// RevocationList is a signed list of revoked licenses.
+// It is distributed as a PEM block of type "LICENSE REVOCATION LIST" and verified offline.
+type RevocationList struct {
+	Number     int64             `json:"num,omitempty"` // Sequence Number
+	IssuedAt   int64             `json:"iat,omitempty"` // Issued At
+	NextUpdate int64             `json:"nxt,omitempty"` // Next Update
+	Entries    []RevocationEntry `json:"ent,omitempty"` // Revoked Licenses
+}
+
+// RevocationEntry identifies a revoked license by ID or by fingerprint.
+type RevocationEntry struct {
+	ID          string           `json:"id,omitempty"`  // License ID
+	Fingerprint string           `json:"fpr,omitempty"` // License Fingerprint
+	Reason      RevocationReason `json:"rsn,omitempty"` // Reason
+	RevokedAt   int64            `json:"rat,omitempty"` // Revoked At
+}
+
+// Encode signs the revocation list with the signer of the license issuer, such as an ed25519.PrivateKey,
+// and returns it PEM encoded.
+func (rl *RevocationList) Encode(signer crypto.Signer) ([]byte, error) {
+	if rl.NextUpdate > 0 && rl.NextUpdate <= rl.IssuedAt {
+		return nil, ErrTime
+	}
+
+	for _, entry := range rl.Entries {
+		if len(entry.ID) == 0 && len(entry.Fingerprint) == 0 {
+			return nil, ErrRevocationEntry
+		}
+	}
+
+	if signer == nil {
+		return nil, ErrPrivateKeyNotDefined
+	}
+
+	data, err := json.Marshal(rl)
+	if err != nil {
+		return nil, err
+	}
+
+	return sealEnvelope(revocationListBlockType, nil, data, signer, EncodeOptions{})
+}
+
+// DecodeRevocationList decodes the PEM encoded revocation list and verifies its signature
+// using the ed25519 public keys. At least one public key is required.
+func DecodeRevocationList(data []byte, publicKeys ...ed25519.PublicKey) (*RevocationList, error) {
+	keyring, err := newDecodeKeyring(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyring == nil || keyring.Len() == 0 {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	dec := decoder{keyring: keyring}
+
+	return dec.decodeRevocationList(data)
+}
+
+// DecodeRevocationListFile decodes the PEM encoded revocation list file and verifies its signature.
+func DecodeRevocationListFile(path string, publicKeys ...ed25519.PublicKey) (*RevocationList, error) {
+	data, err := readFileLimit(path, DefaultDecodeOptions.MaxInputBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeRevocationList(data, publicKeys...)
+}
+
+func (d *decoder) decodeRevocationList(data []byte) (*RevocationList, error) {
+	env, err := d.openEnvelope(data, revocationListBlockType)
+	if err != nil {
+		return nil, err
+	}
+
+	var rl RevocationList
+	if err := json.Unmarshal(env.payload, &rl); err != nil {
+		return nil, malformedError(CodeClaimsInvalid, LayerClaims, err)
+	}
+
+	return &rl, nil
+}
+
+// Lookup returns the entry revoking the license, matched by license ID or fingerprint.
+// If the list has fingerprint entries and the fingerprint of the license can not be computed,
+// the license is treated as revoked by the first of them, so the check fails closed.
+func (rl *RevocationList) Lookup(lic *License) (RevocationEntry, bool) {
+	if rl == nil {
+		return RevocationEntry{}, false
+	}
+
+	var fingerprint string
+
+	for _, entry := range rl.Entries {
+		if len(entry.ID) > 0 && entry.ID == lic.ID {
+			return entry, true
+		}
+
+		if len(entry.Fingerprint) == 0 {
+			continue
+		}
+
+		if len(fingerprint) == 0 {
+			var err error
+			if fingerprint, err = lic.GetFingerprint(); err != nil {
+				return entry, true
+			}
+		}
+
+		if entry.Fingerprint == fingerprint {
+			return entry, true
+		}
+	}
+
+	return RevocationEntry{}, false
+}
+
+// Stale returns true if the next update of the revocation list is due at the given time.
+func (rl *RevocationList) Stale(at time.Time) bool {
+	return rl.NextUpdate > 0 && at.Unix() >= rl.NextUpdate
+}
@@ -0,0 +1,235 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevocationList_Encode_Decode(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	rl := &RevocationList{
+		Number:     3,
+		IssuedAt:   now.Unix(),
+		NextUpdate: now.Add(7 * 24 * time.Hour).Unix(),
+		Entries: []RevocationEntry{
+			{ID: "leaked-license", Reason: ReasonKeyCompromise, RevokedAt: now.Unix()},
+			{Fingerprint: "ECcsha9GmtgfZtn17D76cO4Kx7kfqeBd2prdVKYGID4", Reason: ReasonFraud},
+		},
+	}
+
+	encoded, err := rl.Encode(privateKey)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), "-----BEGIN LICENSE REVOCATION LIST-----")
+
+	t.Run("trusted key", func(t *testing.T) {
+		decoded, err := DecodeRevocationList(encoded, publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, rl, decoded)
+	})
+
+	t.Run("untrusted key", func(t *testing.T) {
+		otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		_, err = DecodeRevocationList(encoded, otherPublicKey)
+		assert.ErrorIs(t, err, ErrVerifySignature)
+	})
+
+	t.Run("public key required", func(t *testing.T) {
+		_, err := DecodeRevocationList(encoded)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+
+		_, err = DecodeRevocationList(encoded, nil)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("license is not a revocation list", func(t *testing.T) {
+		license := &License{ID: "some-license"}
+		encodedLicense, err := license.Encode(privateKey)
+		require.NoError(t, err)
+
+		_, err = DecodeRevocationList(encodedLicense, publicKey)
+		assert.ErrorIs(t, err, ErrMalformedLicense)
+
+		_, err = Decode(encoded, publicKey)
+		assert.ErrorIs(t, err, ErrMalformedLicense)
+	})
+
+	t.Run("from file", func(t *testing.T) {
+		tempFile, err := os.CreateTemp("", "revocation_*.crl")
+		require.NoError(t, err)
+		defer os.Remove(tempFile.Name())
+
+		_, err = tempFile.Write(encoded)
+		require.NoError(t, err)
+		tempFile.Close()
+
+		decoded, err := DecodeRevocationListFile(tempFile.Name(), publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, rl.Number, decoded.Number)
+
+		_, err = DecodeRevocationListFile("nonexistent_file.crl", publicKey)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestRevocationList_Encode_Errors(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		list        *RevocationList
+		privateKey  ed25519.PrivateKey
+		expectedErr error
+	}{
+		{
+			name:        "nil private key",
+			list:        &RevocationList{},
+			expectedErr: ErrPrivateKeyNotDefined,
+		},
+		{
+			name:        "next update before issued",
+			list:        &RevocationList{IssuedAt: 100, NextUpdate: 50},
+			privateKey:  privateKey,
+			expectedErr: ErrTime,
+		},
+		{
+			name:        "entry without id or fingerprint",
+			list:        &RevocationList{Entries: []RevocationEntry{{Reason: ReasonFraud}}},
+			privateKey:  privateKey,
+			expectedErr: ErrRevocationEntry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.list.Encode(tt.privateKey)
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestRevocationList_Lookup(t *testing.T) {
+	license := &License{ID: "123"}
+
+	fingerprint, err := license.GetFingerprint()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		list     *RevocationList
+		expected bool
+	}{
+		{
+			name:     "nil list",
+			list:     nil,
+			expected: false,
+		},
+		{
+			name:     "empty list",
+			list:     &RevocationList{},
+			expected: false,
+		},
+		{
+			name:     "by id",
+			list:     &RevocationList{Entries: []RevocationEntry{{ID: "other"}, {ID: "123"}}},
+			expected: true,
+		},
+		{
+			name:     "by fingerprint",
+			list:     &RevocationList{Entries: []RevocationEntry{{Fingerprint: fingerprint}}},
+			expected: true,
+		},
+		{
+			name:     "no match",
+			list:     &RevocationList{Entries: []RevocationEntry{{ID: "other"}, {Fingerprint: "other"}}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, revoked := tt.list.Lookup(license)
+			assert.Equal(t, tt.expected, revoked)
+		})
+	}
+
+	t.Run("fingerprint error fails closed", func(t *testing.T) {
+		invalid := &License{ID: "456", Data: json.RawMessage(`{`)}
+
+		_, err := invalid.GetFingerprint()
+		require.Error(t, err)
+
+		entry, revoked := (&RevocationList{Entries: []RevocationEntry{{ID: "other"}, {Fingerprint: "other"}}}).Lookup(invalid)
+		assert.True(t, revoked)
+		assert.Equal(t, "other", entry.Fingerprint)
+
+		_, revoked = (&RevocationList{Entries: []RevocationEntry{{ID: "other"}}}).Lookup(invalid)
+		assert.False(t, revoked)
+	})
+}
+
+func TestRevocationList_Stale(t *testing.T) {
+	at := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.False(t, (&RevocationList{}).Stale(at))
+	assert.False(t, (&RevocationList{NextUpdate: at.Add(time.Hour).Unix()}).Stale(at))
+	assert.True(t, (&RevocationList{NextUpdate: at.Unix()}).Stale(at))
+}
+
+func TestVerifier_WithRevocationList(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	revoked := &License{ID: "revoked-license"}
+	revokedEncoded, err := revoked.Encode(privateKey)
+	require.NoError(t, err)
+
+	valid := &License{ID: "valid-license"}
+	validEncoded, err := valid.Encode(privateKey)
+	require.NoError(t, err)
+
+	rl := &RevocationList{
+		Entries: []RevocationEntry{
+			{ID: "revoked-license", Reason: ReasonKeyCompromise},
+		},
+	}
+
+	encodedList, err := rl.Encode(privateKey)
+	require.NoError(t, err)
+
+	verifier, err := NewVerifier(publicKey)
+	require.NoError(t, err)
+
+	decodedList, err := verifier.VerifyRevocationList(encodedList)
+	require.NoError(t, err)
+
+	verifier = verifier.WithRevocationList(decodedList)
+
+	_, err = verifier.Verify(revokedEncoded)
+	assert.ErrorIs(t, err, ErrLicenseRevoked)
+
+	var revokedErr *RevokedError
+	require.ErrorAs(t, err, &revokedErr)
+	assert.Equal(t, ReasonKeyCompromise, revokedErr.Entry.Reason)
+	assert.Equal(t, "license revoked: key_compromise", err.Error())
+
+	decoded, err := verifier.Verify(validEncoded)
+	require.NoError(t, err)
+	assert.Equal(t, valid.ID, decoded.ID)
+
+	_, err = (&Verifier{}).VerifyRevocationList(encodedList)
+	assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+}
@@ -0,0 +1,91 @@
+package license
+
+import (
+	"fmt"
+	"time"
+)
+
+// State is the commercial state of a license at a point in time.
+type State int
+
+const (
+	// StateValid licenses are within their term.
+	StateValid State = iota + 1
+	// StateNotYetValid licenses are before their NotBefore time.
+	StateNotYetValid
+	// StateExpiringSoon licenses expire within the ExpiringSoon window.
+	StateExpiringSoon
+	// StateInGrace licenses are expired but within the grace period; products should warn instead of locking.
+	StateInGrace
+	// StateExpired licenses are expired and past the grace period.
+	StateExpired
+)
+
+func (s State) String() string {
+	switch s {
+	case StateValid:
+		return "valid"
+	case StateNotYetValid:
+		return "not_yet_valid"
+	case StateExpiringSoon:
+		return "expiring_soon"
+	case StateInGrace:
+		return "in_grace"
+	case StateExpired:
+		return "expired"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// Status is the state of a license with the remaining durations.
+type Status struct {
+	State State
+	// Remaining is the time until ExpiredAt; zero once expired or if the license never expires.
+	Remaining time.Duration
+	// GraceRemaining is the time until the grace period ends; zero outside the grace period.
+	GraceRemaining time.Duration
+}
+
+// State returns the state of the license at the given time.
+// The grace period, the expiring soon window and the clock skew are taken from the options and
+// applied as by ValidateAt, so a license is expired exactly when ValidateAt reports ErrExpired;
+// the options clock is ignored.
+func (lic *License) State(at time.Time, opts ValidateOptions) Status {
+	if lic.NotBefore > 0 && at.Add(opts.Skew).Unix() < lic.NotBefore {
+		return Status{State: StateNotYetValid, Remaining: lic.remaining(at)}
+	}
+
+	if lic.ExpiredAt <= 0 {
+		return Status{State: StateValid}
+	}
+
+	// The license may expire and leave its grace period up to Skew late.
+	at = at.Add(-opts.Skew)
+
+	expiredAt := time.Unix(lic.ExpiredAt, 0)
+
+	if !lic.ExpiredAsOf(at) {
+		remaining := expiredAt.Sub(at)
+
+		if opts.ExpiringSoon > 0 && remaining <= opts.ExpiringSoon {
+			return Status{State: StateExpiringSoon, Remaining: remaining}
+		}
+
+		return Status{State: StateValid, Remaining: remaining}
+	}
+
+	if graceEnd := lic.graceEnd(opts); at.Before(graceEnd) {
+		return Status{State: StateInGrace, GraceRemaining: graceEnd.Sub(at)}
+	}
+
+	return Status{State: StateExpired}
+}
+
+func (lic *License) remaining(at time.Time) time.Duration {
+	if lic.ExpiredAt <= 0 {
+		return 0
+	}
+
+	return time.Unix(lic.ExpiredAt, 0).Sub(at)
+}
@@ -0,0 +1,178 @@
+package license
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestState_String(t *testing.T) {
+	tests := []struct {
+		state    State
+		expected string
+	}{
+		{StateValid, "valid"},
+		{StateNotYetValid, "not_yet_valid"},
+		{StateExpiringSoon, "expiring_soon"},
+		{StateInGrace, "in_grace"},
+		{StateExpired, "expired"},
+		{State(0), "State(0)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.state.String())
+		})
+	}
+}
+
+func TestLicense_State(t *testing.T) {
+	day := 24 * time.Hour
+	expiredAt := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	license := &License{
+		ID:        "state-license",
+		IssuedAt:  expiredAt.Add(-365 * day).Unix(),
+		ExpiredAt: expiredAt.Unix(),
+	}
+
+	opts := ValidateOptions{
+		GracePeriod:  14 * day,
+		ExpiringSoon: 30 * day,
+	}
+
+	tests := []struct {
+		name     string
+		license  *License
+		at       time.Time
+		opts     ValidateOptions
+		expected Status
+	}{
+		{
+			name:     "valid",
+			license:  license,
+			at:       expiredAt.Add(-60 * day),
+			opts:     opts,
+			expected: Status{State: StateValid, Remaining: 60 * day},
+		},
+		{
+			name:     "expiring soon",
+			license:  license,
+			at:       expiredAt.Add(-10 * day),
+			opts:     opts,
+			expected: Status{State: StateExpiringSoon, Remaining: 10 * day},
+		},
+		{
+			name:     "no expiring soon window",
+			license:  license,
+			at:       expiredAt.Add(-10 * day),
+			expected: Status{State: StateValid, Remaining: 10 * day},
+		},
+		{
+			name:     "in grace",
+			license:  license,
+			at:       expiredAt.Add(4 * day),
+			opts:     opts,
+			expected: Status{State: StateInGrace, GraceRemaining: 10 * day},
+		},
+		{
+			name:     "expired after grace",
+			license:  license,
+			at:       expiredAt.Add(14 * day),
+			opts:     opts,
+			expected: Status{State: StateExpired},
+		},
+		{
+			name:     "expired without grace",
+			license:  license,
+			at:       expiredAt,
+			expected: Status{State: StateExpired},
+		},
+		{
+			name: "grace claim takes precedence",
+			license: &License{
+				ID:          "state-license",
+				ExpiredAt:   expiredAt.Unix(),
+				GracePeriod: int64((30 * day).Seconds()),
+			},
+			at:       expiredAt.Add(20 * day),
+			opts:     opts,
+			expected: Status{State: StateInGrace, GraceRemaining: 10 * day},
+		},
+		{
+			name: "not yet valid",
+			license: &License{
+				ID:        "state-license",
+				NotBefore: expiredAt.Add(-100 * day).Unix(),
+				ExpiredAt: expiredAt.Unix(),
+			},
+			at:       expiredAt.Add(-200 * day),
+			opts:     opts,
+			expected: Status{State: StateNotYetValid, Remaining: 200 * day},
+		},
+		{
+			name:     "never expires",
+			license:  &License{ID: "state-license"},
+			at:       expiredAt,
+			opts:     opts,
+			expected: Status{State: StateValid},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.license.State(tt.at, tt.opts))
+		})
+	}
+
+	t.Run("skew agrees with validate", func(t *testing.T) {
+		notBefore := &License{
+			ID:        "state-license",
+			NotBefore: expiredAt.Add(-100 * day).Unix(),
+			ExpiredAt: expiredAt.Unix(),
+		}
+
+		tests := []struct {
+			name    string
+			license *License
+			at      time.Time
+			opts    ValidateOptions
+		}{
+			{name: "expired within skew", license: license, at: expiredAt.Add(time.Second), opts: ValidateOptions{Skew: time.Minute}},
+			{name: "expired past skew", license: license, at: expiredAt.Add(2 * time.Minute), opts: ValidateOptions{Skew: time.Minute}},
+			{name: "grace within skew", license: license, at: expiredAt.Add(14*day + time.Second), opts: ValidateOptions{GracePeriod: 14 * day, Skew: time.Minute}},
+			{name: "not before within skew", license: notBefore, at: expiredAt.Add(-100*day - time.Second), opts: ValidateOptions{Skew: time.Minute}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				state := tt.license.State(tt.at, tt.opts).State
+				err := tt.license.ValidateAt(tt.at, tt.opts)
+
+				assert.Equal(t, errors.Is(err, ErrExpired), state == StateExpired)
+				assert.Equal(t, errors.Is(err, ErrNotYetValid), state == StateNotYetValid)
+			})
+		}
+
+		status := license.State(expiredAt.Add(time.Second), ValidateOptions{Skew: time.Minute})
+		assert.Equal(t, Status{State: StateValid, Remaining: time.Minute - time.Second}, status)
+	})
+}
+
+func TestLicense_ValidateAt_GracePeriod(t *testing.T) {
+	expiredAt := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	license := &License{
+		ID:        "grace-license",
+		ExpiredAt: expiredAt.Unix(),
+	}
+
+	opts := ValidateOptions{GracePeriod: 14 * 24 * time.Hour}
+
+	assert.NoError(t, license.ValidateAt(expiredAt.Add(time.Hour), opts))
+	assert.ErrorIs(t, license.ValidateAt(expiredAt.Add(15*24*time.Hour), opts), ErrExpired)
+	assert.ErrorIs(t, license.ValidateAt(expiredAt.Add(time.Hour), ValidateOptions{}), ErrExpired)
+	assert.True(t, license.ExpiredAsOf(expiredAt.Add(time.Hour)))
+}
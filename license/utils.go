@@ -10,8 +10,23 @@ import (
 
 type licenseContent struct {
 	Data     string `json:"d"`
-	Sign     string `json:"s"`
-	DataHash string `json:"h"`
+	Sign     string `json:"s,omitempty"`
+	DataHash string `json:"h,omitempty"`
+	KeyID    string `json:"k,omitempty"`
+
+	Nonce      string             `json:"n,omitempty"`
+	Recipients []recipientContent `json:"r,omitempty"`
+
+	Signatures []signatureContent `json:"g,omitempty"`
+
+	Certificates []string `json:"c,omitempty"`
+}
+
+// signatureContent is a co-signature of the license data.
+type signatureContent struct {
+	KeyID     string `json:"k"`
+	Sign      string `json:"s"`
+	Algorithm string `json:"a,omitempty"` // Ed25519 if empty
 }
 
 func compress(data []byte) ([]byte, error) {
@@ -34,15 +49,30 @@ func compress(data []byte) ([]byte, error) {
 }
 
 func decompress(data []byte) ([]byte, error) {
+	return decompressLimit(data, -1)
+}
+
+// decompressLimit decompresses the data and fails with a LimitError once more than limit bytes are produced.
+// A negative limit disables the check.
+func decompressLimit(data []byte, limit int64) ([]byte, error) {
 	zr := flate.NewReader(bytes.NewReader(data))
 
 	defer zr.Close()
 
-	decompressed, err := io.ReadAll(zr)
+	var reader io.Reader = zr
+	if limit >= 0 {
+		reader = io.LimitReader(zr, limit+1)
+	}
+
+	decompressed, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, err
 	}
 
+	if exceeds(int64(len(decompressed)), limit) {
+		return nil, &LimitError{Limit: "decompressed", Max: limit}
+	}
+
 	return decompressed, nil
 }
 
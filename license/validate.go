@@ -0,0 +1,152 @@
+package license
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Clock provides the current time for license validation.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface.
+type ClockFunc func() time.Time
+
+// Now returns f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock backed by time.Now.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// ValidateOptions configures license validation.
+type ValidateOptions struct {
+	// Clock provides the validation time. SystemClock is used if nil.
+	Clock Clock
+	// Skew is the tolerated clock difference between issuer and product:
+	// IssuedAt and NotBefore may be up to Skew in the future and ExpiredAt up to Skew in the past.
+	Skew time.Duration
+	// GracePeriod is the time after ExpiredAt during which the license is still accepted.
+	// The GracePeriod claim of the license takes precedence.
+	GracePeriod time.Duration
+	// ExpiringSoon is the time before ExpiredAt during which State reports StateExpiringSoon.
+	ExpiringSoon time.Duration
+	// MachineComponents are the component hashes of the host, checked against the license machine binding.
+	MachineComponents []string
+	// RequiredClaims lists claim names (e.g. "cus", "exp") that must be set. The license ID is always required.
+	RequiredClaims []string
+}
+
+func (opts ValidateOptions) now() time.Time {
+	if opts.Clock == nil {
+		return SystemClock.Now()
+	}
+
+	return opts.Clock.Now()
+}
+
+// ExpiredAsOf returns true if the license is expired at the given time.
+func (lic *License) ExpiredAsOf(t time.Time) bool {
+	return lic.ExpiredAt > 0 && t.Unix() >= lic.ExpiredAt
+}
+
+// Validate checks the license claims at the time provided by the options clock.
+// If any check fails, a *ValidationError listing every failed check is returned.
+func (lic *License) Validate(opts ValidateOptions) error {
+	return lic.ValidateAt(opts.now(), opts)
+}
+
+// ValidateAt checks the license claims as of the given time.
+// The options clock is ignored.
+func (lic *License) ValidateAt(t time.Time, opts ValidateOptions) error {
+	var errs []error
+
+	if len(lic.ID) == 0 {
+		errs = append(errs, ErrLicenseIDNotDefined)
+	}
+
+	if len(opts.RequiredClaims) > 0 {
+		missing, err := lic.missingClaims(opts.RequiredClaims)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range missing {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrMissingClaim, name))
+		}
+	}
+
+	if lic.ExpiredAt > 0 && lic.ExpiredAt <= lic.IssuedAt {
+		errs = append(errs, ErrTime)
+	}
+
+	if lic.ExpiredAt > 0 && lic.ExpiredAt <= lic.NotBefore {
+		errs = append(errs, ErrNotBeforeTime)
+	}
+
+	if lic.IssuedAt > 0 && t.Add(opts.Skew).Unix() < lic.IssuedAt {
+		errs = append(errs, ErrIssuedInFuture)
+	}
+
+	if lic.NotBefore > 0 && t.Add(opts.Skew).Unix() < lic.NotBefore {
+		errs = append(errs, ErrNotYetValid)
+	}
+
+	if lic.ExpiredAt > 0 && !t.Add(-opts.Skew).Before(lic.graceEnd(opts)) {
+		errs = append(errs, ErrExpired)
+	}
+
+	if lic.Machine != nil && !lic.Machine.Matches(opts.MachineComponents) {
+		errs = append(errs, ErrMachineMismatch)
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// gracePeriod returns the grace period from the license claim or, if unset, from the options.
+func (lic *License) gracePeriod(opts ValidateOptions) time.Duration {
+	if lic.GracePeriod > 0 {
+		return time.Duration(lic.GracePeriod) * time.Second
+	}
+
+	if opts.GracePeriod > 0 {
+		return opts.GracePeriod
+	}
+
+	return 0
+}
+
+// graceEnd returns the time the grace period ends. It must only be called if ExpiredAt is set.
+func (lic *License) graceEnd(opts ValidateOptions) time.Time {
+	return time.Unix(lic.ExpiredAt, 0).Add(lic.gracePeriod(opts))
+}
+
+// missingClaims returns the names of the claims that are not set in the license.
+func (lic *License) missingClaims(names []string) ([]string, error) {
+	licData, err := json.Marshal(lic)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]json.RawMessage
+	if err := json.Unmarshal(licData, &claims); err != nil {
+		return nil, err
+	}
+
+	var missing []string
+
+	for _, name := range names {
+		if _, ok := claims[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+
+	return missing, nil
+}
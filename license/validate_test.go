@@ -0,0 +1,203 @@
+package license
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockFunc(t *testing.T) {
+	fixed := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := ClockFunc(func() time.Time { return fixed })
+
+	assert.Equal(t, fixed, clock.Now())
+	assert.WithinDuration(t, time.Now(), SystemClock.Now(), time.Second)
+}
+
+func TestLicense_ExpiredAsOf(t *testing.T) {
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		license  *License
+		expected bool
+	}{
+		{
+			name:     "no expiration",
+			license:  &License{},
+			expected: false,
+		},
+		{
+			name:     "expires later",
+			license:  &License{ExpiredAt: at.Add(time.Second).Unix()},
+			expected: false,
+		},
+		{
+			name:     "expires exactly at",
+			license:  &License{ExpiredAt: at.Unix()},
+			expected: true,
+		},
+		{
+			name:     "expired before",
+			license:  &License{ExpiredAt: at.Add(-time.Hour).Unix()},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.license.ExpiredAsOf(at))
+		})
+	}
+}
+
+func TestLicense_ValidateAt(t *testing.T) {
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		license  *License
+		skew     time.Duration
+		expected error
+	}{
+		{
+			name: "valid",
+			license: &License{
+				ID:        "validate-license",
+				IssuedAt:  at.Add(-time.Hour).Unix(),
+				ExpiredAt: at.Add(time.Hour).Unix(),
+			},
+		},
+		{
+			name: "expired",
+			license: &License{
+				ID:        "validate-license",
+				IssuedAt:  at.Add(-2 * time.Hour).Unix(),
+				ExpiredAt: at.Add(-time.Minute).Unix(),
+			},
+			expected: ErrExpired,
+		},
+		{
+			name: "expired within skew",
+			license: &License{
+				ID:        "validate-license",
+				IssuedAt:  at.Add(-2 * time.Hour).Unix(),
+				ExpiredAt: at.Add(-time.Minute).Unix(),
+			},
+			skew: 5 * time.Minute,
+		},
+		{
+			name: "issued in the future",
+			license: &License{
+				ID:       "validate-license",
+				IssuedAt: at.Add(time.Minute).Unix(),
+			},
+			expected: ErrIssuedInFuture,
+		},
+		{
+			name: "issued in the future within skew",
+			license: &License{
+				ID:       "validate-license",
+				IssuedAt: at.Add(time.Minute).Unix(),
+			},
+			skew: 5 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.license.ValidateAt(at, ValidateOptions{Skew: tt.skew})
+			if tt.expected != nil {
+				assert.ErrorIs(t, err, tt.expected)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLicense_Validate(t *testing.T) {
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := ClockFunc(func() time.Time { return at })
+
+	license := &License{
+		ID:        "validate-license",
+		IssuedAt:  at.Add(-time.Hour).Unix(),
+		ExpiredAt: at.Add(time.Hour).Unix(),
+	}
+
+	assert.NoError(t, license.Validate(ValidateOptions{Clock: clock}))
+
+	later := ClockFunc(func() time.Time { return at.Add(2 * time.Hour) })
+	assert.ErrorIs(t, license.Validate(ValidateOptions{Clock: later}), ErrExpired)
+
+	assert.NoError(t, (&License{ID: "validate-license"}).Validate(ValidateOptions{}))
+}
+
+func TestLicense_ValidateAt_NotBefore(t *testing.T) {
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	renewal := &License{
+		ID:        "renewal-license",
+		IssuedAt:  at.Add(-time.Hour).Unix(),
+		NotBefore: at.Add(24 * time.Hour).Unix(),
+		ExpiredAt: at.Add(365 * 24 * time.Hour).Unix(),
+	}
+
+	assert.ErrorIs(t, renewal.ValidateAt(at, ValidateOptions{}), ErrNotYetValid)
+	assert.NoError(t, renewal.ValidateAt(at.Add(24*time.Hour), ValidateOptions{}))
+	assert.NoError(t, renewal.ValidateAt(at.Add(23*time.Hour), ValidateOptions{Skew: 2 * time.Hour}))
+}
+
+func TestLicense_ValidateAt_Pipeline(t *testing.T) {
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("every failed check is reported", func(t *testing.T) {
+		license := &License{
+			IssuedAt:  at.Add(time.Hour).Unix(),
+			NotBefore: at.Add(time.Hour).Unix(),
+			ExpiredAt: at.Add(-time.Hour).Unix(),
+		}
+
+		err := license.ValidateAt(at, ValidateOptions{RequiredClaims: []string{"cus", "iat"}})
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Len(t, validationErr.Errors, 7)
+
+		for _, expected := range []error{
+			ErrLicenseIDNotDefined,
+			ErrMissingClaim,
+			ErrTime,
+			ErrNotBeforeTime,
+			ErrIssuedInFuture,
+			ErrNotYetValid,
+			ErrExpired,
+		} {
+			assert.ErrorIs(t, err, expected)
+		}
+
+		assert.Contains(t, err.Error(), "missing required claim: cus")
+		assert.NotContains(t, err.Error(), "missing required claim: iat")
+	})
+
+	t.Run("required claims present", func(t *testing.T) {
+		license := &License{
+			ID:       "required-license",
+			Customer: "customer-123",
+			Type:     "premium",
+		}
+
+		assert.NoError(t, license.ValidateAt(at, ValidateOptions{RequiredClaims: []string{"id", "cus", "typ"}}))
+	})
+}
+
+func TestValidationError(t *testing.T) {
+	err := &ValidationError{Errors: []error{ErrExpired, ErrNotYetValid}}
+	assert.Equal(t, "license validation failed: license expired; license not yet valid", err.Error())
+	assert.ErrorIs(t, err, ErrExpired)
+	assert.ErrorIs(t, err, ErrNotYetValid)
+	assert.NotErrorIs(t, err, ErrIssuedInFuture)
+}
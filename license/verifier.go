@@ -0,0 +1,168 @@
+package license
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+)
+
+// Verifier decodes licenses and always verifies the content signature against a trusted key set.
+// Unlike Decode, a Verifier never returns a license whose signature was not checked.
+type Verifier struct {
+	dec            decoder
+	revocationList *RevocationList
+}
+
+// NewVerifier returns a Verifier trusting the given ed25519 public keys.
+// At least one public key is required.
+func NewVerifier(publicKeys ...ed25519.PublicKey) (*Verifier, error) {
+	keyring := NewKeyring()
+
+	for _, key := range publicKeys {
+		if key == nil {
+			continue
+		}
+
+		if _, err := keyring.Add(key, KeyActive); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewKeyringVerifier(keyring)
+}
+
+// NewKeyringVerifier returns a Verifier trusting the keys of the keyring.
+// The keyring must not be empty. Later status changes in the keyring apply to the verifier.
+func NewKeyringVerifier(keyring *Keyring) (*Verifier, error) {
+	if keyring == nil || keyring.Len() == 0 {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	return &Verifier{
+		dec: decoder{keyring: keyring},
+	}, nil
+}
+
+// WithRecipientKeys returns a copy of the verifier that decrypts licenses encrypted to recipients
+// using the given X25519 private keys.
+func (v *Verifier) WithRecipientKeys(keys ...*ecdh.PrivateKey) *Verifier {
+	nv := *v
+	nv.dec.recipientKeys = append([]*ecdh.PrivateKey(nil), keys...)
+
+	return &nv
+}
+
+// WithDecodeOptions returns a copy of the verifier that bounds decoding with the given options.
+// A zero Threshold keeps the threshold of the verifier.
+func (v *Verifier) WithDecodeOptions(opts DecodeOptions) *Verifier {
+	if opts.Threshold == 0 {
+		opts.Threshold = v.dec.opts.Threshold
+	}
+
+	nv := *v
+	nv.dec.opts = opts
+
+	return &nv
+}
+
+// WithThreshold returns a copy of the verifier that requires valid signatures from at least m
+// distinct trusted keys, as set by DecodeOptions.Threshold.
+func (v *Verifier) WithThreshold(m int) *Verifier {
+	nv := *v
+	nv.dec.opts.Threshold = m
+
+	return &nv
+}
+
+// WithRevocationList returns a copy of the verifier that rejects licenses listed in the revocation list
+// with a *RevokedError.
+func (v *Verifier) WithRevocationList(rl *RevocationList) *Verifier {
+	nv := *v
+	nv.revocationList = rl
+
+	return &nv
+}
+
+// VerifyRevocationList decodes the PEM encoded revocation list and verifies its signature
+// against the keys trusted by the verifier.
+func (v *Verifier) VerifyRevocationList(data []byte) (*RevocationList, error) {
+	if !v.ready() {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	return v.dec.decodeRevocationList(data)
+}
+
+// VerifyBlock decodes a PEM block of the given type encoded with EncodeBlock and verifies the payload
+// signature against the keys trusted by the verifier. It returns the unsigned PEM headers and the payload.
+func (v *Verifier) VerifyBlock(data []byte, blockType string) (map[string]string, []byte, error) {
+	if !v.ready() {
+		return nil, nil, ErrPublicKeyNotDefined
+	}
+
+	env, err := v.dec.openEnvelope(data, blockType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := v.dec.checkThreshold(env.signers); err != nil {
+		return nil, nil, err
+	}
+
+	return env.block.Headers, env.payload, nil
+}
+
+// VerifyBlockFile is like VerifyBlock but reads the PEM block from a file, bounded by the
+// MaxInputBytes decode option of the verifier.
+func (v *Verifier) VerifyBlockFile(path, blockType string) (map[string]string, []byte, error) {
+	if !v.ready() {
+		return nil, nil, ErrPublicKeyNotDefined
+	}
+
+	data, err := readFileLimit(path, v.dec.opts.withDefaults().MaxInputBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return v.VerifyBlock(data, blockType)
+}
+
+// Verify decodes the PEM encoded license key and verifies the content signature.
+func (v *Verifier) Verify(data []byte) (*License, error) {
+	if !v.ready() {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	lic, err := v.dec.decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.checkRevoked(lic)
+}
+
+// VerifyFile decodes the PEM encoded license file and verifies the content signature.
+func (v *Verifier) VerifyFile(path string) (*License, error) {
+	if !v.ready() {
+		return nil, ErrPublicKeyNotDefined
+	}
+
+	data, err := readFileLimit(path, v.dec.opts.withDefaults().MaxInputBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Verify(data)
+}
+
+// checkRevoked returns the license unless the revocation list of the verifier lists it.
+func (v *Verifier) checkRevoked(lic *License) (*License, error) {
+	if entry, revoked := v.revocationList.Lookup(lic); revoked {
+		return nil, &RevokedError{Entry: entry}
+	}
+
+	return lic, nil
+}
+
+func (v *Verifier) ready() bool {
+	return v != nil && v.dec.keyring != nil && v.dec.keyring.Len() > 0
+}
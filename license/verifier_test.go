@@ -0,0 +1,237 @@
+package license
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVerifier(t *testing.T) {
+	t.Run("no public keys", func(t *testing.T) {
+		_, err := NewVerifier()
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("only nil public keys", func(t *testing.T) {
+		_, err := NewVerifier(nil, nil)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("invalid public key size", func(t *testing.T) {
+		_, err := NewVerifier(ed25519.PublicKey([]byte("short")))
+		assert.ErrorIs(t, err, ErrInvalidPublicKey)
+	})
+
+	t.Run("valid public key", func(t *testing.T) {
+		publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		verifier, err := NewVerifier(nil, publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, 1, verifier.dec.keyring.Len())
+	})
+
+	t.Run("public keys are copied", func(t *testing.T) {
+		publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		verifier, err := NewVerifier(publicKey)
+		require.NoError(t, err)
+
+		publicKey[0] ^= 0xff
+		assert.NotEqual(t, publicKey, verifier.dec.keyring.Keys()[0].PublicKey)
+	})
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{
+		ID:        "verifier-license",
+		IssuedAt:  time.Now().Unix(),
+		ExpiredAt: time.Now().Add(time.Hour).Unix(),
+		Data:      []byte(`{"test":1}`),
+	}
+
+	encoded, err := license.Encode(privateKey)
+	require.NoError(t, err)
+
+	t.Run("trusted key", func(t *testing.T) {
+		verifier, err := NewVerifier(publicKey)
+		require.NoError(t, err)
+
+		decoded, err := verifier.Verify(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+		assert.Equal(t, license.Data, decoded.Data)
+		assert.Equal(t, KeyID(publicKey), decoded.SignedBy())
+	})
+
+	t.Run("keyring verifier", func(t *testing.T) {
+		keyring := NewKeyring()
+		keyID, err := keyring.Add(publicKey, KeyActive)
+		require.NoError(t, err)
+
+		verifier, err := NewKeyringVerifier(keyring)
+		require.NoError(t, err)
+
+		decoded, err := verifier.Verify(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, keyID, decoded.SignedBy())
+
+		require.NoError(t, keyring.SetStatus(keyID, KeyRevoked))
+
+		_, err = verifier.Verify(encoded)
+		assert.ErrorIs(t, err, ErrKeyRevoked)
+	})
+
+	t.Run("empty keyring verifier", func(t *testing.T) {
+		_, err := NewKeyringVerifier(NewKeyring())
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+
+		_, err = NewKeyringVerifier(nil)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+
+	t.Run("untrusted key", func(t *testing.T) {
+		otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		verifier, err := NewVerifier(otherPublicKey)
+		require.NoError(t, err)
+
+		decoded, err := verifier.Verify(encoded)
+		assert.ErrorIs(t, err, ErrVerifySignature)
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("zero value verifier", func(t *testing.T) {
+		decoded, err := (&Verifier{}).Verify(encoded)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("nil verifier", func(t *testing.T) {
+		var verifier *Verifier
+
+		decoded, err := verifier.Verify(encoded)
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("malformed license", func(t *testing.T) {
+		verifier, err := NewVerifier(publicKey)
+		require.NoError(t, err)
+
+		_, err = verifier.Verify([]byte("not a license"))
+		assert.ErrorIs(t, err, ErrMalformedLicense)
+	})
+}
+
+func TestVerifier_VerifyFile(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{
+		ID: "verifier-file-license",
+	}
+
+	encoded, err := license.Encode(privateKey)
+	require.NoError(t, err)
+
+	tempFile, err := os.CreateTemp("", "verifier_license_*.lic")
+	require.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(encoded)
+	require.NoError(t, err)
+	tempFile.Close()
+
+	verifier, err := NewVerifier(publicKey)
+	require.NoError(t, err)
+
+	t.Run("successful file verify", func(t *testing.T) {
+		decoded, err := verifier.VerifyFile(tempFile.Name())
+		require.NoError(t, err)
+		assert.Equal(t, license.ID, decoded.ID)
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		_, err := verifier.VerifyFile("nonexistent_file.lic")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("zero value verifier", func(t *testing.T) {
+		_, err := (&Verifier{}).VerifyFile(tempFile.Name())
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+}
+
+func TestVerifier_VerifyBlockFile(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	payload := []byte(`{"custom":"payload"}`)
+
+	encoded, err := EncodeBlock("CUSTOM BLOCK", nil, payload, privateKey)
+	require.NoError(t, err)
+
+	tempFile, err := os.CreateTemp("", "verifier_block_*.pem")
+	require.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(encoded)
+	require.NoError(t, err)
+	tempFile.Close()
+
+	verifier, err := NewVerifier(publicKey)
+	require.NoError(t, err)
+
+	t.Run("successful file verify", func(t *testing.T) {
+		_, decoded, err := verifier.VerifyBlockFile(tempFile.Name(), "CUSTOM BLOCK")
+		require.NoError(t, err)
+		assert.Equal(t, payload, decoded)
+	})
+
+	t.Run("file too large", func(t *testing.T) {
+		_, _, err := verifier.WithDecodeOptions(DecodeOptions{MaxInputBytes: 16}).VerifyBlockFile(tempFile.Name(), "CUSTOM BLOCK")
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		_, _, err := verifier.VerifyBlockFile("nonexistent_file.pem", "CUSTOM BLOCK")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("zero value verifier", func(t *testing.T) {
+		_, _, err := (&Verifier{}).VerifyBlockFile(tempFile.Name(), "CUSTOM BLOCK")
+		assert.ErrorIs(t, err, ErrPublicKeyNotDefined)
+	})
+}
+
+func TestDecodeUnverified(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	license := &License{
+		ID:   "unverified-license",
+		Data: []byte(`{"test":1}`),
+	}
+
+	encoded, err := license.Encode(privateKey)
+	require.NoError(t, err)
+
+	decoded, err := DecodeUnverified(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, license.ID, decoded.ID)
+	assert.Equal(t, license.Data, decoded.Data)
+
+	_, err = DecodeUnverified(nil)
+	assert.ErrorIs(t, err, ErrMalformedLicense)
+}